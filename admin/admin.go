@@ -0,0 +1,201 @@
+// Package admin implements the admin API that lists installations, shows
+// repository configs and lists and re-processes webhook deliveries.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/frozzare/hellobot/bot"
+)
+
+// Bot represents the bot methods used by the admin API.
+type Bot interface {
+	Installations() ([]bot.AppInstallation, error)
+	RepositoryConfig(int, string, string) (*bot.RepositoryConfig, error)
+	Deliveries() ([]bot.Delivery, error)
+	Delivery(string) (*bot.Delivery, error)
+	Redeliver(string) (*bot.Delivery, error)
+}
+
+// Handler represents the admin API http handler.
+type Handler struct {
+	bot   Bot
+	token string
+}
+
+// NewHandler creates a new admin API handler that authenticates requests
+// with the bearer token.
+func NewHandler(b Bot, token string) *Handler {
+	return &Handler{bot: b, token: token}
+}
+
+// ServeHTTP serves the admin API routes:
+//
+//	GET  /admin/installations
+//	GET  /admin/installations/{id}/repos/{owner}/{repo}/config
+//	GET  /admin/deliveries?limit=50
+//	GET  /admin/deliveries/{id}
+//	POST /admin/deliveries/{id}/redeliver
+//	GET  /admin/schemas/{name}.json
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authenticated(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="hellobot"`)
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin"), "/"), "/")
+
+	switch {
+	case r.Method == "GET" && len(parts) == 1 && parts[0] == "installations":
+		h.installations(w, r)
+	case r.Method == "GET" && len(parts) == 6 && parts[0] == "installations" && parts[2] == "repos" && parts[5] == "config":
+		h.config(w, r, parts[1], parts[3], parts[4])
+	case r.Method == "GET" && len(parts) == 1 && parts[0] == "deliveries":
+		h.deliveries(w, r)
+	case r.Method == "GET" && len(parts) == 2 && parts[0] == "deliveries":
+		h.delivery(w, r, parts[1])
+	case r.Method == "POST" && len(parts) == 3 && parts[0] == "deliveries" && parts[2] == "redeliver":
+		h.redeliver(w, r, parts[1])
+	case r.Method == "GET" && len(parts) == 2 && parts[0] == "schemas":
+		h.schema(w, r, strings.TrimSuffix(parts[1], ".json"))
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// authenticated returns true if the request has the bearer token.
+func (h *Handler) authenticated(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return len(h.token) > 0 && subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+// installations writes the installations with their repositories.
+func (h *Handler) installations(w http.ResponseWriter, r *http.Request) {
+	installations, err := h.bot.Installations()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, installations)
+}
+
+// config writes the effective repository config and the problems with it.
+func (h *Handler) config(w http.ResponseWriter, r *http.Request, installation, owner, repo string) {
+	id, err := strconv.Atoi(installation)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid installation id")
+		return
+	}
+
+	config, err := h.bot.RepositoryConfig(id, owner, repo)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, config)
+}
+
+// deliveries writes the most recent deliveries, newest first.
+func (h *Handler) deliveries(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+
+	if s := r.URL.Query().Get("limit"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+
+		limit = n
+	}
+
+	deliveries, err := h.bot.Deliveries()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].Time.After(deliveries[j].Time)
+	})
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	if deliveries == nil {
+		deliveries = []bot.Delivery{}
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// delivery writes the delivery with the payload.
+func (h *Handler) delivery(w http.ResponseWriter, r *http.Request, id string) {
+	delivery, err := h.bot.Delivery(id)
+	if err == bot.ErrDeliveryNotFound {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, delivery)
+}
+
+// redeliver handles the delivery again and writes the delivery with the new outcome.
+func (h *Handler) redeliver(w http.ResponseWriter, r *http.Request, id string) {
+	delivery, err := h.bot.Redeliver(id)
+	if err == bot.ErrDeliveryNotFound {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err == bot.ErrQueueFull {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, delivery)
+}
+
+// schema writes the JSON schema of an endpoint response.
+func (h *Handler) schema(w http.ResponseWriter, r *http.Request, name string) {
+	schema, ok := schemas[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write([]byte(schema))
+}
+
+// writeJSON writes the value as JSON with the status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the error message as JSON with the status code.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
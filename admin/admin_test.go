@@ -0,0 +1,219 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/frozzare/hellobot/bot"
+)
+
+type fakeBot struct {
+	deliveries  []bot.Delivery
+	redelivered []string
+}
+
+func (f *fakeBot) Installations() ([]bot.AppInstallation, error) {
+	return []bot.AppInstallation{{ID: 1, Account: "frozzare", Repositories: []string{"frozzare/hellobot"}}}, nil
+}
+
+func (f *fakeBot) RepositoryConfig(installation int, owner, repo string) (*bot.RepositoryConfig, error) {
+	return &bot.RepositoryConfig{
+		Repository:   owner + "/" + repo,
+		Installation: installation,
+		Config:       map[string]interface{}{"issue": map[string]interface{}{"message": "Hello"}},
+		Errors:       []string{"issue: unknown reaction smile"},
+	}, nil
+}
+
+func (f *fakeBot) Deliveries() ([]bot.Delivery, error) {
+	return f.deliveries, nil
+}
+
+func (f *fakeBot) Delivery(id string) (*bot.Delivery, error) {
+	for _, delivery := range f.deliveries {
+		if delivery.ID == id {
+			delivery.Payload = json.RawMessage(`{"action":"opened"}`)
+			return &delivery, nil
+		}
+	}
+
+	return nil, bot.ErrDeliveryNotFound
+}
+
+func (f *fakeBot) Redeliver(id string) (*bot.Delivery, error) {
+	f.redelivered = append(f.redelivered, id)
+	return f.Delivery(id)
+}
+
+// validate validates the value against the subset of JSON schema used by the schemas.
+func validate(schema map[string]interface{}, v interface{}, path string) error {
+	if t, ok := schema["type"]; ok {
+		types, ok := t.([]interface{})
+		if !ok {
+			types = []interface{}{t}
+		}
+
+		valid := false
+		for _, t := range types {
+			valid = valid || matchesType(t.(string), v)
+		}
+
+		if !valid {
+			return fmt.Errorf("%s: expected %v, got %T", path, t, v)
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := v[name.(string)]; !ok {
+					return fmt.Errorf("%s: missing %s", path, name)
+				}
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, value := range v {
+			property, ok := properties[name].(map[string]interface{})
+			if !ok && schema["additionalProperties"] == false {
+				return fmt.Errorf("%s: unknown property %s", path, name)
+			}
+
+			if err := validate(property, value, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		for i, value := range v {
+			if err := validate(items, value, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func matchesType(t string, v interface{}) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return t == "object"
+	case []interface{}:
+		return t == "array"
+	case string:
+		return t == "string"
+	case float64:
+		return t == "number" || (t == "integer" && v == float64(int64(v)))
+	case bool:
+		return t == "boolean"
+	case nil:
+		return t == "null"
+	}
+
+	return false
+}
+
+func TestHandler(t *testing.T) {
+	now := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeBot{deliveries: []bot.Delivery{
+		{ID: "a", Time: now, Event: "issues", Repository: "frozzare/hellobot"},
+		{ID: "b", Time: now.Add(time.Minute), Event: "release", Error: "Release disabled"},
+		{ID: "d", Time: now.Add(2 * time.Minute), Event: "issues", Error: "No GitHub client", Failed: true},
+	}}
+
+	h := NewHandler(fake, "secret")
+
+	tests := []struct {
+		method string
+		path   string
+		token  string
+		status int
+		schema string
+	}{
+		{"GET", "/admin/installations", "", http.StatusUnauthorized, "error"},
+		{"GET", "/admin/installations", "wrong", http.StatusUnauthorized, "error"},
+		{"GET", "/admin/installations", "secret", http.StatusOK, "installations"},
+		{"GET", "/admin/installations/1/repos/frozzare/hellobot/config", "secret", http.StatusOK, "config"},
+		{"GET", "/admin/installations/x/repos/frozzare/hellobot/config", "secret", http.StatusBadRequest, "error"},
+		{"GET", "/admin/deliveries", "secret", http.StatusOK, "deliveries"},
+		{"GET", "/admin/deliveries?limit=0", "secret", http.StatusBadRequest, "error"},
+		{"GET", "/admin/deliveries/a", "secret", http.StatusOK, "delivery"},
+		{"GET", "/admin/deliveries/d", "secret", http.StatusOK, "delivery"},
+		{"GET", "/admin/deliveries/c", "secret", http.StatusNotFound, "error"},
+		{"POST", "/admin/deliveries/b/redeliver", "secret", http.StatusOK, "delivery"},
+		{"POST", "/admin/deliveries/c/redeliver", "secret", http.StatusNotFound, "error"},
+		{"DELETE", "/admin/deliveries/a", "secret", http.StatusNotFound, "error"},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, nil)
+		if len(test.token) > 0 {
+			r.Header.Set("Authorization", "Bearer "+test.token)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.path, test.status, w.Code)
+			continue
+		}
+
+		var schema map[string]interface{}
+		if err := json.Unmarshal([]byte(schemas[test.schema]), &schema); err != nil {
+			t.Fatalf("%s schema: %s", test.schema, err)
+		}
+
+		var v interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+			t.Fatalf("%s %s: %s", test.method, test.path, err)
+		}
+
+		if err := validate(schema, v, "$"); err != nil {
+			t.Errorf("%s %s: %s", test.method, test.path, err)
+		}
+	}
+
+	if len(fake.redelivered) != 2 || fake.redelivered[0] != "b" {
+		t.Fatalf("Expected delivery b to be redelivered, got %v", fake.redelivered)
+	}
+}
+
+func TestHandlerDeliveriesOrder(t *testing.T) {
+	now := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeBot{deliveries: []bot.Delivery{
+		{ID: "a", Time: now, Event: "issues"},
+		{ID: "b", Time: now.Add(time.Minute), Event: "issues"},
+		{ID: "c", Time: now.Add(2 * time.Minute), Event: "issues"},
+	}}
+
+	r := httptest.NewRequest("GET", "/admin/deliveries?limit=2", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+
+	w := httptest.NewRecorder()
+	NewHandler(fake, "secret").ServeHTTP(w, r)
+
+	var deliveries []bot.Delivery
+	if err := json.NewDecoder(w.Body).Decode(&deliveries); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliveries) != 2 || deliveries[0].ID != "c" || deliveries[1].ID != "b" {
+		t.Fatalf("Expected newest deliveries first, got %v", deliveries)
+	}
+}
+
+func TestSchemas(t *testing.T) {
+	for name, schema := range schemas {
+		var v map[string]interface{}
+		if err := json.Unmarshal([]byte(schema), &v); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+}
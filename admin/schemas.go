@@ -0,0 +1,78 @@
+package admin
+
+// schemas contains the JSON schemas of the admin API responses.
+var schemas = map[string]string{
+	"error": `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "Error",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["error"],
+  "properties": {
+    "error": {"type": "string"}
+  }
+}`,
+	"installations": `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "Installations",
+  "type": "array",
+  "items": {
+    "type": "object",
+    "additionalProperties": false,
+    "required": ["id", "account", "repositories"],
+    "properties": {
+      "id": {"type": "integer"},
+      "account": {"type": "string"},
+      "repositories": {"type": "array", "items": {"type": "string"}},
+      "error": {"type": "string"}
+    }
+  }
+}`,
+	"config": `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "Repository config",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["repository", "installation", "config", "errors"],
+  "properties": {
+    "repository": {"type": "string"},
+    "installation": {"type": "integer"},
+    "config": {"type": ["object", "null"]},
+    "errors": {"type": "array", "items": {"type": "string"}}
+  }
+}`,
+	"deliveries": `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "Deliveries",
+  "type": "array",
+  "items": {
+    "type": "object",
+    "additionalProperties": false,
+    "required": ["id", "time", "event"],
+    "properties": {
+      "id": {"type": "string"},
+      "time": {"type": "string"},
+      "event": {"type": "string"},
+      "repository": {"type": "string"},
+      "error": {"type": "string"},
+      "failed": {"type": "boolean"}
+    }
+  }
+}`,
+	"delivery": `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "Delivery",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["id", "time", "event"],
+  "properties": {
+    "id": {"type": "string"},
+    "time": {"type": "string"},
+    "event": {"type": "string"},
+    "repository": {"type": "string"},
+    "error": {"type": "string"},
+    "failed": {"type": "boolean"},
+    "payload": {"type": "object"}
+  }
+}`,
+}
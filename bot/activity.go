@@ -0,0 +1,272 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// deliveryRetention is how long handled deliveries are kept for deduplication.
+const deliveryRetention = 7 * 24 * time.Hour
+
+// activityRetention is how long actions taken on issues and pull requests are kept.
+const activityRetention = 180 * 24 * time.Hour
+
+// Activity represents an action the bot has taken on an issue or pull request.
+type Activity struct {
+	Time       time.Time `json:"time"`
+	Repository string    `json:"repository"`
+	Number     int       `json:"number"`
+	Action     string    `json:"action"`
+	Actor      string    `json:"actor,omitempty"`
+}
+
+// Delivery represents a handled webhook delivery.
+type Delivery struct {
+	ID         string          `json:"id"`
+	Time       time.Time       `json:"time"`
+	Event      string          `json:"event"`
+	Repository string          `json:"repository,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Failed     bool            `json:"failed,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// ErrDeliveryNotFound is returned when a delivery don't exists.
+var ErrDeliveryNotFound = errors.New("Delivery not found")
+
+// repository returns the full name of the repository in the payload.
+func (b *Bot) repository() string {
+	if b.payload == nil || len(b.payload.Repository.Name) == 0 {
+		return ""
+	}
+
+	return b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name
+}
+
+// record records an action taken on the issue or pull request.
+func (b *Bot) record(number int, action string) error {
+	activity := Activity{
+		Time:       b.now().UTC(),
+		Repository: b.repository(),
+		Number:     number,
+		Action:     action,
+		Actor:      b.payload.Sender.Login,
+	}
+
+	buf, err := json.Marshal(activity)
+	if err != nil {
+		return errors.Wrap(err, "marshal activity")
+	}
+
+	// Numbers and times are padded so keys are sorted by issue and time.
+	key := fmt.Sprintf("activity/%s/%010d/%020d", activity.Repository, number, activity.Time.UnixNano())
+
+	return errors.Wrap(b.getStore().Set(key, buf), "set activity")
+}
+
+// Activity returns the recorded actions for the repository, like `owner/repo`,
+// sorted by issue number and time. All issues are returned when number is zero.
+func (b *Bot) Activity(repository string, number int) ([]Activity, error) {
+	prefix := "activity/" + repository + "/"
+	if number > 0 {
+		prefix += fmt.Sprintf("%010d/", number)
+	}
+
+	var activities []Activity
+
+	err := b.eachValue(prefix, func(buf []byte) error {
+		var activity Activity
+		if err := json.Unmarshal(buf, &activity); err != nil {
+			return errors.Wrap(err, "unmarshal activity")
+		}
+
+		activities = append(activities, activity)
+
+		return nil
+	})
+
+	return activities, err
+}
+
+// greetedKey returns the store key for a greeted author in the repository.
+func (b *Bot) greetedKey(login string) string {
+	return "greeted/" + b.repository() + "/" + strings.ToLower(login)
+}
+
+// greeted returns true if the author has been greeted in the repository before.
+func (b *Bot) greeted(login string) (bool, error) {
+	buf, err := b.getStore().Get(b.greetedKey(login))
+	if err != nil {
+		return false, errors.Wrap(err, "get greeted")
+	}
+
+	return buf != nil, nil
+}
+
+// recordGreeted records that the author has been greeted in the repository.
+func (b *Bot) recordGreeted(login string) error {
+	now := b.now().UTC().Format(time.RFC3339)
+
+	return errors.Wrap(b.getStore().Set(b.greetedKey(login), []byte(now)), "set greeted")
+}
+
+// delivered returns true if the webhook delivery has already been handled
+// or skipped. Failed deliveries are handled again when GitHub redelivers them.
+func (b *Bot) delivered(id string) (bool, error) {
+	delivery, err := b.Delivery(id)
+	if err == ErrDeliveryNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return !delivery.Failed, nil
+}
+
+// recordDelivery records the handled webhook delivery, the payload so it can
+// be handled again and the handling error. Failed deliveries are recorded
+// for the admin API but are not marked as handled.
+func (b *Bot) recordDelivery(id, event string, body []byte, handleErr error) error {
+	delivery := Delivery{
+		ID:         id,
+		Time:       b.now().UTC(),
+		Event:      event,
+		Repository: b.repository(),
+	}
+
+	if json.Valid(body) {
+		delivery.Payload = body
+	}
+
+	if handleErr != nil {
+		delivery.Error = handleErr.Error()
+		delivery.Failed = !IsSkip(handleErr)
+	}
+
+	buf, err := json.Marshal(delivery)
+	if err != nil {
+		return errors.Wrap(err, "marshal delivery")
+	}
+
+	return errors.Wrap(b.getStore().Set("deliveries/"+id, buf), "set delivery")
+}
+
+// Delivery returns the recorded webhook delivery with the payload.
+func (b *Bot) Delivery(id string) (*Delivery, error) {
+	buf, err := b.getStore().Get("deliveries/" + id)
+	if err != nil {
+		return nil, errors.Wrap(err, "get delivery")
+	}
+
+	if buf == nil {
+		return nil, ErrDeliveryNotFound
+	}
+
+	var delivery *Delivery
+	if err := json.Unmarshal(buf, &delivery); err != nil {
+		return nil, errors.Wrap(err, "unmarshal delivery")
+	}
+
+	return delivery, nil
+}
+
+// Deliveries returns the recorded webhook deliveries, without payloads, sorted by time.
+func (b *Bot) Deliveries() ([]Delivery, error) {
+	var deliveries []Delivery
+
+	err := b.eachValue("deliveries/", func(buf []byte) error {
+		var delivery Delivery
+		if err := json.Unmarshal(buf, &delivery); err != nil {
+			return errors.Wrap(err, "unmarshal delivery")
+		}
+
+		delivery.Payload = nil
+		deliveries = append(deliveries, delivery)
+
+		return nil
+	})
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].Time.Before(deliveries[j].Time)
+	})
+
+	return deliveries, err
+}
+
+// pruneDeliveries deletes deliveries older than the retention.
+func (b *Bot) pruneDeliveries() error {
+	deliveries, err := b.Deliveries()
+	if err != nil {
+		return err
+	}
+
+	before := b.now().Add(-deliveryRetention)
+
+	for _, delivery := range deliveries {
+		if !delivery.Time.Before(before) {
+			break
+		}
+
+		if err := b.getStore().Delete("deliveries/" + delivery.ID); err != nil {
+			return errors.Wrap(err, "delete delivery")
+		}
+	}
+
+	return nil
+}
+
+// pruneActivity deletes actions older than the retention, the time of the
+// action is the last part of the key.
+func (b *Bot) pruneActivity() error {
+	keys, err := b.getStore().Keys("activity/")
+	if err != nil {
+		return errors.Wrap(err, "list keys")
+	}
+
+	before := b.now().Add(-activityRetention).UnixNano()
+
+	for _, key := range keys {
+		nanos, err := strconv.ParseInt(key[strings.LastIndex(key, "/")+1:], 10, 64)
+		if err != nil || nanos >= before {
+			continue
+		}
+
+		if err := b.getStore().Delete(key); err != nil {
+			return errors.Wrap(err, "delete activity")
+		}
+	}
+
+	return nil
+}
+
+// eachValue calls fn with the value of each key that starts with the prefix.
+func (b *Bot) eachValue(prefix string, fn func([]byte) error) error {
+	keys, err := b.getStore().Keys(prefix)
+	if err != nil {
+		return errors.Wrap(err, "list keys")
+	}
+
+	for _, key := range keys {
+		buf, err := b.getStore().Get(key)
+		if err != nil {
+			return errors.Wrap(err, "get "+key)
+		}
+
+		if buf == nil {
+			continue
+		}
+
+		if err := fn(buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBotActivity(t *testing.T) {
+	now := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+	client := newClient(nil)
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore(), clock: func() time.Time { return now }}
+
+	r := newEventRequest("issues", issueOpenedPayload)
+	r.Header.Set("X-GitHub-Delivery", "72d3162e")
+
+	if err := b.Handle(r); err != nil {
+		t.Fatal(err)
+	}
+
+	// Redeliveries are not handled again.
+	r = newEventRequest("issues", issueOpenedPayload)
+	r.Header.Set("X-GitHub-Delivery", "72d3162e")
+
+	if err := b.Handle(r); err == nil || err.Error() != "Delivery 72d3162e already handled" {
+		t.Fatalf("Expected delivery already handled error, got %v", err)
+	}
+
+	if comments := client.Issues.(*githubIssues).comments; len(comments) != 1 {
+		t.Fatalf("Expected one comment, got %v", comments)
+	}
+
+	if greeted, _ := b.greeted("TEST"); !greeted {
+		t.Fatal("Expected author to be greeted")
+	}
+
+	activities, err := b.Activity("test/Fredrik", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(activities) != 1 || activities[0].Action != "greeted" || activities[0].Actor != "test" || !activities[0].Time.Equal(now) {
+		t.Fatalf("Unexpected activities %v", activities)
+	}
+
+	deliveries, err := b.Deliveries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliveries) != 1 || deliveries[0].Event != "issues" || deliveries[0].Repository != "test/Fredrik" || len(deliveries[0].Error) > 0 {
+		t.Fatalf("Unexpected deliveries %v", deliveries)
+	}
+
+	now = now.Add(deliveryRetention + time.Minute)
+
+	if err := b.pruneDeliveries(); err != nil {
+		t.Fatal(err)
+	}
+
+	if deliveries, _ := b.Deliveries(); len(deliveries) != 0 {
+		t.Fatalf("Expected old deliveries to be pruned, got %v", deliveries)
+	}
+
+	// Actions are kept longer than deliveries.
+	if err := b.pruneActivity(); err != nil {
+		t.Fatal(err)
+	}
+
+	if activities, _ := b.Activity("test/Fredrik", 0); len(activities) != 1 {
+		t.Fatalf("Expected activities to be kept, got %v", activities)
+	}
+
+	now = now.Add(activityRetention)
+
+	if err := b.pruneActivity(); err != nil {
+		t.Fatal(err)
+	}
+
+	if activities, _ := b.Activity("test/Fredrik", 0); len(activities) != 0 {
+		t.Fatalf("Expected old activities to be pruned, got %v", activities)
+	}
+}
+
+func TestBotFailedDelivery(t *testing.T) {
+	client := newClient(nil)
+	client.Issues.(*githubIssues).failOn = 1234
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	deliver := func() error {
+		r := newEventRequest("issues", issueOpenedPayload)
+		r.Header.Set("X-GitHub-Delivery", "72d3162e")
+		return b.Handle(r)
+	}
+
+	if err := deliver(); err == nil {
+		t.Fatal("Expected comment error")
+	}
+
+	delivery, err := b.Delivery("72d3162e")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !delivery.Failed || len(delivery.Payload) == 0 {
+		t.Fatalf("Expected failed delivery with payload, got %+v", delivery)
+	}
+
+	// Failed deliveries are handled again when GitHub redelivers them.
+	client.Issues.(*githubIssues).failOn = 0
+
+	if err := deliver(); err != nil {
+		t.Fatal(err)
+	}
+
+	if comments := client.Issues.(*githubIssues).comments; len(comments) != 1 {
+		t.Fatalf("Expected one comment, got %v", comments)
+	}
+}
@@ -0,0 +1,136 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// AppInstallation represents an installation of the app and the repositories
+// it has access to.
+type AppInstallation struct {
+	ID           int64    `json:"id"`
+	Account      string   `json:"account"`
+	Repositories []string `json:"repositories"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// RepositoryConfig represents the effective config of a repository and the
+// problems with it.
+type RepositoryConfig struct {
+	Repository   string                 `json:"repository"`
+	Installation int                    `json:"installation"`
+	Config       map[string]interface{} `json:"config"`
+	Errors       []string               `json:"errors"`
+}
+
+// Installations returns all installations of the app with their repositories.
+func (b *Bot) Installations() ([]AppInstallation, error) {
+	ctx := context.Background()
+
+	app, err := b.createAppClient()
+	if err != nil {
+		return nil, err
+	}
+
+	installations, err := b.listInstallations(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []AppInstallation{}
+
+	for _, installation := range installations {
+		i := AppInstallation{
+			ID:           installation.GetID(),
+			Account:      installation.GetAccount().GetLogin(),
+			Repositories: []string{},
+		}
+
+		client, err := b.installationClient(int(i.ID))
+		if err == nil {
+			repos, err := b.listRepos(ctx, client)
+			for _, repo := range repos {
+				i.Repositories = append(i.Repositories, repo.GetFullName())
+			}
+
+			if err != nil {
+				i.Error = err.Error()
+			}
+		} else {
+			i.Error = err.Error()
+		}
+
+		list = append(list, i)
+	}
+
+	return list, nil
+}
+
+// RepositoryConfig returns the effective config of the repository, with
+// defaults for missing values, and the problems with it.
+func (b *Bot) RepositoryConfig(installation int, owner, name string) (*RepositoryConfig, error) {
+	client, err := b.installationClient(installation)
+	if err != nil {
+		return nil, err
+	}
+
+	rb := b.repositoryBot(context.Background(), client, installation, owner, name)
+
+	rc := &RepositoryConfig{
+		Repository:   owner + "/" + name,
+		Installation: installation,
+		Errors:       []string{},
+	}
+
+	config, err := rb.downloadConfig()
+	if err != nil {
+		rc.Errors = append(rc.Errors, err.Error())
+		return rc, nil
+	}
+
+	if config == nil {
+		config = &Config{}
+	}
+
+	rc.Errors = append(rc.Errors, config.problems()...)
+
+	// The config is converted through YAML so the keys matches the config file.
+	buf, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal yaml")
+	}
+
+	var v interface{}
+	if err := yaml.Unmarshal(buf, &v); err != nil {
+		return nil, errors.Wrap(err, "unmarshal yaml")
+	}
+
+	rc.Config, _ = jsonValue(v).(map[string]interface{})
+
+	return rc, nil
+}
+
+// jsonValue converts YAML maps, that can have any keys, to maps that can be
+// encoded as JSON.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonValue(value)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
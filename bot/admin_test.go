@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestBotRepositoryConfig(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+issue:
+  message: Hello @{author}!
+  reaction: smile
+commands:
+  label: maintainer
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: map[int]*githubClient{1: client}}
+
+	rc, err := b.RepositoryConfig(1, "frozzare", "hellobot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"commands: unknown role maintainer", "issue: unknown reaction smile"}
+	if !reflect.DeepEqual(rc.Errors, expected) {
+		t.Fatalf("Expected %v, got %v", expected, rc.Errors)
+	}
+
+	issue, _ := rc.Config["issue"].(map[string]interface{})
+	if issue["message"] != "Hello @{author}!" {
+		t.Fatalf("Unexpected config %v", rc.Config)
+	}
+
+	// Missing values are included with defaults.
+	if _, ok := rc.Config["stale"]; !ok {
+		t.Fatalf("Expected stale config, got %v", rc.Config)
+	}
+}
@@ -0,0 +1,340 @@
+package bot
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// codeownersFiles contains the locations GitHub looks for a CODEOWNERS file in.
+var codeownersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersRule represents a line in a CODEOWNERS file.
+type codeownersRule struct {
+	pattern string
+	owners  []string
+}
+
+// parseCodeowners parses the rules in a CODEOWNERS file.
+func parseCodeowners(data []byte) []codeownersRule {
+	var rules []codeownersRule
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		rule := codeownersRule{pattern: fields[0]}
+
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+
+			// Owners can be emails, but only users and teams can be requested.
+			if strings.HasPrefix(owner, "@") {
+				rule.owners = append(rule.owners, strings.TrimPrefix(owner, "@"))
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// codeowners returns the owners of the files changed in the pull request.
+// The last matching rule in the CODEOWNERS file takes precedence.
+func (b *Bot) codeowners(number int) ([]string, error) {
+	var data []byte
+
+	for _, name := range codeownersFiles {
+		buf, err := b.downloadFile(name)
+		if err == nil {
+			data = buf
+			break
+		}
+	}
+
+	if data == nil {
+		return nil, nil
+	}
+
+	rules := parseCodeowners(data)
+
+	files, err := b.listFiles(number)
+	if err != nil {
+		return nil, err
+	}
+
+	var owners []string
+
+	for _, file := range files {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if matchPath(rules[i].pattern, file) {
+				owners = appendUnique(owners, rules[i].owners...)
+				break
+			}
+		}
+	}
+
+	return owners, nil
+}
+
+// candidates returns the users and teams from the assign configuration
+// in the order they should be selected, without the author. Teams are
+// replaced by their members when teams are not allowed.
+func (b *Bot) candidates(key string, assign Assign, number int, teams bool) ([]string, error) {
+	var candidates []string
+
+	if assign.Codeowners && b.payload.IsPullRequest() {
+		owners, err := b.codeowners(number)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates = appendUnique(candidates, owners...)
+	}
+
+	candidates = appendUnique(candidates, assign.Users...)
+	candidates = appendUnique(candidates, assign.Team...)
+
+	if !teams {
+		var members []string
+
+		for _, candidate := range candidates {
+			if !strings.Contains(candidate, "/") {
+				members = appendUnique(members, candidate)
+				continue
+			}
+
+			m, err := b.teamMembers(candidate)
+			if err != nil {
+				return nil, err
+			}
+
+			members = appendUnique(members, m...)
+		}
+
+		candidates = members
+	}
+
+	for i := 0; i < len(candidates); i++ {
+		if strings.ToLower(candidates[i]) == strings.ToLower(b.payload.Sender.Login) {
+			candidates = append(candidates[:i], candidates[i+1:]...)
+			i--
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	max := assign.Max
+	if max <= 0 || max > len(candidates) {
+		max = len(candidates)
+	}
+
+	switch assign.Strategy {
+	case "", "all":
+	case "round_robin":
+		var err error
+		candidates, err = b.roundRobin(key, candidates, max)
+		if err != nil {
+			return nil, err
+		}
+	case "load_balance":
+		var err error
+		candidates, err = b.loadBalance(candidates)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("Unknown strategy %s", assign.Strategy)
+	}
+
+	return candidates[:max], nil
+}
+
+// roundRobin rotates the candidates to start after the last selected
+// candidate and stores the last of the selected candidates. The login is
+// stored, not the position, so changes to the candidates don't select
+// someone again.
+func (b *Bot) roundRobin(key string, candidates []string, selected int) ([]string, error) {
+	key = "round_robin/" + b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name + "/" + key
+
+	buf, err := b.getStore().Get(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "get round robin position")
+	}
+
+	// Candidates start from the beginning when the last one is removed.
+	pos := 0
+	for i, candidate := range candidates {
+		if strings.ToLower(candidate) == strings.ToLower(string(buf)) {
+			pos = (i + 1) % len(candidates)
+			break
+		}
+	}
+
+	rotated := append(append([]string{}, candidates[pos:]...), candidates[:pos]...)
+
+	if err := b.getStore().Set(key, []byte(rotated[selected-1])); err != nil {
+		return nil, errors.Wrap(err, "set round robin position")
+	}
+
+	return rotated, nil
+}
+
+// loadBalance sorts the candidates by the number of open issues and pull
+// requests they are assigned to. Teams are always placed last.
+func (b *Bot) loadBalance(candidates []string) ([]string, error) {
+	if b.client == nil || b.client.Issues == nil {
+		return nil, errors.New("No GitHub client")
+	}
+
+	load := map[string]int{}
+
+	for _, candidate := range candidates {
+		if strings.Contains(candidate, "/") {
+			load[candidate] = int(^uint(0) >> 1)
+			continue
+		}
+
+		opts := &github.IssueListByRepoOptions{
+			State:       "open",
+			Assignee:    candidate,
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+
+		for {
+			issues, res, err := b.client.Issues.ListByRepo(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, opts)
+			if err != nil {
+				return nil, errors.Wrap(err, "github list issues by repo")
+			}
+
+			load[candidate] += len(issues)
+
+			if res == nil || res.NextPage == 0 {
+				break
+			}
+
+			opts.Page = res.NextPage
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return load[candidates[i]] < load[candidates[j]]
+	})
+
+	return candidates, nil
+}
+
+// teamMembers returns the logins of the members of the team, like
+// `hellobot/docs`.
+func (b *Bot) teamMembers(team string) ([]string, error) {
+	if b.client == nil || b.client.Organizations == nil {
+		return nil, errors.New("No GitHub client")
+	}
+
+	parts := strings.SplitN(team, "/", 2)
+
+	id, err := b.teamID(parts[0], parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var members []string
+	opts := &github.OrganizationListTeamMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		users, res, err := b.client.Organizations.ListTeamMembers(b.ctx, id, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "github list team members")
+		}
+
+		for _, user := range users {
+			members = append(members, user.GetLogin())
+		}
+
+		if res == nil || res.NextPage == 0 {
+			return members, nil
+		}
+
+		opts.Page = res.NextPage
+	}
+}
+
+// teamID returns the id of the team with the slug in the organization.
+func (b *Bot) teamID(org, slug string) (int64, error) {
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		teams, res, err := b.client.Organizations.ListTeams(b.ctx, org, opts)
+		if err != nil {
+			return 0, errors.Wrap(err, "github list teams")
+		}
+
+		for _, team := range teams {
+			if strings.ToLower(team.GetSlug()) == strings.ToLower(slug) {
+				return team.GetID(), nil
+			}
+		}
+
+		if res == nil || res.NextPage == 0 {
+			return 0, errors.Errorf("Unknown team %s/%s", org, slug)
+		}
+
+		opts.Page = res.NextPage
+	}
+}
+
+// requestReviewers requests reviewers for the pull request.
+func (b *Bot) requestReviewers(assign Assign, number int) error {
+	candidates, err := b.candidates("reviewers", assign, number, true)
+	if err != nil {
+		return err
+	}
+
+	var req github.ReviewersRequest
+
+	for _, candidate := range candidates {
+		if i := strings.Index(candidate, "/"); i != -1 {
+			req.TeamReviewers = append(req.TeamReviewers, candidate[i+1:])
+		} else {
+			req.Reviewers = append(req.Reviewers, candidate)
+		}
+	}
+
+	if len(req.Reviewers) == 0 && len(req.TeamReviewers) == 0 {
+		return nil
+	}
+
+	_, _, err = b.client.PullRequests.RequestReviewers(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, req)
+
+	return errors.Wrap(err, "github request reviewers")
+}
+
+// addAssignees adds assignees to the issue or pull request.
+func (b *Bot) addAssignees(assign Assign, number int) error {
+	// Teams can't be assigned, so they are replaced by their members.
+	assignees, err := b.candidates("assignees", assign, number, false)
+	if err != nil {
+		return err
+	}
+
+	if len(assignees) == 0 {
+		return nil
+	}
+
+	_, _, err = b.client.Issues.AddAssignees(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, assignees)
+
+	return errors.Wrap(err, "github add assignees")
+}
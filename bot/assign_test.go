@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestParseCodeowners(t *testing.T) {
+	rules := parseCodeowners([]byte(`
+# Default owners
+*       @frozzare
+/docs/  @hellobot/docs docs@example.com
+*.go    @gopher @octocat # Go code
+`))
+
+	expected := []codeownersRule{
+		{pattern: "*", owners: []string{"frozzare"}},
+		{pattern: "/docs/", owners: []string{"hellobot/docs"}},
+		{pattern: "*.go", owners: []string{"gopher", "octocat"}},
+	}
+
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("Expected %v, got %v", expected, rules)
+	}
+}
+
+func TestBotReviewersCodeowners(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{
+		config: `
+pull_request:
+  message: Hello
+  reviewers:
+    codeowners: true
+    max: 2
+`,
+		files: map[string]string{
+			".github/CODEOWNERS": "*  @frozzare\n/docs/ @hellobot/docs\n*.go @octocat @gopher",
+		},
+	}
+	client.PullRequests = &githubPullRequests{files: [][]string{{"bot/bot.go", "docs/index.md"}}}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	if err := b.SayHello(newRequest(pullRequestOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The author octocat is skipped and max limits the reviewers.
+	expected := []github.ReviewersRequest{{Reviewers: []string{"gopher"}, TeamReviewers: []string{"docs"}}}
+	if reviewers := client.PullRequests.(*githubPullRequests).reviewers; !reflect.DeepEqual(reviewers, expected) {
+		t.Fatalf("Expected reviewers %v, got %v", expected, reviewers)
+	}
+}
+
+func TestBotAssigneesRoundRobin(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+issue:
+  message: Hello
+  assignees:
+    team:
+      - alice
+      - bob
+      - test
+      - carol
+    strategy: round_robin
+    max: 1
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	for i := 0; i < 4; i++ {
+		if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The author test is skipped.
+	expected := [][]string{{"alice"}, {"bob"}, {"carol"}, {"alice"}}
+	if assignees := client.Issues.(*githubIssues).assignees; !reflect.DeepEqual(assignees, expected) {
+		t.Fatalf("Expected assignees %v, got %v", expected, assignees)
+	}
+}
+
+func TestBotAssigneesLoadBalance(t *testing.T) {
+	client := newClient(nil)
+	client.Issues = &githubIssues{assigned: map[string]int{"alice": 3, "bob": 1, "carol": 2}}
+	client.Repositories = &githubRepositories{config: `
+issue:
+  message: Hello
+  assignees:
+    team:
+      - alice
+      - bob
+      - carol
+    strategy: load_balance
+    max: 2
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{{"bob", "carol"}}
+	if assignees := client.Issues.(*githubIssues).assignees; !reflect.DeepEqual(assignees, expected) {
+		t.Fatalf("Expected assignees %v, got %v", expected, assignees)
+	}
+}
+
+func TestBotAssigneesRoundRobinChanged(t *testing.T) {
+	repositories := &githubRepositories{config: `
+issue:
+  message: Hello
+  assignees:
+    team:
+      - alice
+      - bob
+    strategy: round_robin
+    max: 1
+`}
+	client := newClient(nil)
+	client.Repositories = repositories
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new candidate before the last selected doesn't select it again.
+	repositories.config = strings.Replace(repositories.config, "- alice", "- aaron\n      - alice", 1)
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{{"alice"}, {"bob"}}
+	if assignees := client.Issues.(*githubIssues).assignees; !reflect.DeepEqual(assignees, expected) {
+		t.Fatalf("Expected assignees %v, got %v", expected, assignees)
+	}
+}
+
+func TestBotAssigneesLoadBalancePages(t *testing.T) {
+	client := newClient(nil)
+	client.Issues = &githubIssues{assigned: map[string]int{"alice": 150, "bob": 120, "carol": 2}}
+	client.Repositories = &githubRepositories{config: `
+issue:
+  message: Hello
+  assignees:
+    team:
+      - alice
+      - bob
+      - carol
+    strategy: load_balance
+    max: 2
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{{"carol", "bob"}}
+	if assignees := client.Issues.(*githubIssues).assignees; !reflect.DeepEqual(assignees, expected) {
+		t.Fatalf("Expected assignees %v, got %v", expected, assignees)
+	}
+}
+
+func TestBotAssigneesTeam(t *testing.T) {
+	client := newClient(nil)
+	client.Organizations = &githubOrganizations{teams: map[string][]string{
+		"core": {"frozzare"},
+		"docs": {"alice", "test", "bob"},
+	}}
+	client.Repositories = &githubRepositories{config: `
+issue:
+  message: Hello
+  assignees:
+    team:
+      - hellobot/docs
+      - carol
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Team members are assigned instead of the team, without the author.
+	expected := [][]string{{"alice", "bob", "carol"}}
+	if assignees := client.Issues.(*githubIssues).assignees; !reflect.DeepEqual(assignees, expected) {
+		t.Fatalf("Expected assignees %v, got %v", expected, assignees)
+	}
+}
@@ -3,46 +3,200 @@ package bot
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v2"
 
 	"github.com/bradleyfalzon/ghinstallation"
+	"github.com/frozzare/hellobot/trace"
 	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
 )
 
 type githubIssuesService interface {
+	AddAssignees(context.Context, string, string, int, []string) (*github.Issue, *github.Response, error)
 	AddLabelsToIssue(context.Context, string, string, int, []string) ([]*github.Label, *github.Response, error)
+	Create(context.Context, string, string, *github.IssueRequest) (*github.Issue, *github.Response, error)
 	CreateComment(context.Context, string, string, int, *github.IssueComment) (*github.IssueComment, *github.Response, error)
+	Edit(context.Context, string, string, int, *github.IssueRequest) (*github.Issue, *github.Response, error)
+	ListByRepo(context.Context, string, string, *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error)
+	Lock(context.Context, string, string, int) (*github.Response, error)
+	RemoveLabelForIssue(context.Context, string, string, int, string) (*github.Response, error)
+}
+
+type githubChecksService interface {
+	CreateCheckRun(context.Context, string, string, *CheckRun) (*CheckRun, *github.Response, error)
+}
+
+type githubGraphQLService interface {
+	Query(context.Context, string, map[string]interface{}, interface{}) error
+}
+
+type githubOrganizationsService interface {
+	IsMember(context.Context, string, string) (bool, *github.Response, error)
+	ListTeamMembers(context.Context, int64, *github.OrganizationListTeamMembersOptions) ([]*github.User, *github.Response, error)
+	ListTeams(context.Context, string, *github.ListOptions) ([]*github.Team, *github.Response, error)
+}
+
+type githubPullRequestsService interface {
+	Get(context.Context, string, string, int) (*github.PullRequest, *github.Response, error)
+	ListCommits(context.Context, string, string, int, *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error)
+	ListFiles(context.Context, string, string, int, *github.ListOptions) ([]*github.CommitFile, *github.Response, error)
+	RequestReviewers(context.Context, string, string, int, github.ReviewersRequest) (*github.PullRequest, *github.Response, error)
+}
+
+type githubAppsService interface {
+	ListInstallations(context.Context, *github.ListOptions) ([]*github.Installation, *github.Response, error)
+	ListRepos(context.Context, *github.ListOptions) ([]*github.Repository, *github.Response, error)
+}
+
+type githubReactionsService interface {
+	CreateIssueCommentReaction(context.Context, string, string, int64, string) (*github.Reaction, *github.Response, error)
+	CreateIssueReaction(context.Context, string, string, int, string) (*github.Reaction, *github.Response, error)
 }
 
 type githubRepositoriesService interface {
+	CompareCommits(context.Context, string, string, string, string) (*github.CommitsComparison, *github.Response, error)
+	CreateStatus(context.Context, string, string, string, *github.RepoStatus) (*github.RepoStatus, *github.Response, error)
 	DownloadContents(context.Context, string, string, string, *github.RepositoryContentGetOptions) (io.ReadCloser, error)
+	GetPermissionLevel(context.Context, string, string, string) (*github.RepositoryPermissionLevel, *github.Response, error)
+	IsCollaborator(context.Context, string, string, string) (bool, *github.Response, error)
 }
 
 type githubClient struct {
-	Issues       githubIssuesService
-	Repositories githubRepositoriesService
+	Apps          githubAppsService
+	Checks        githubChecksService
+	GraphQL       githubGraphQLService
+	Issues        githubIssuesService
+	Organizations githubOrganizationsService
+	PullRequests  githubPullRequestsService
+	Reactions     githubReactionsService
+	Repositories  githubRepositoriesService
 }
 
 // Bot represents the bot.
 type Bot struct {
-	id      int
-	cert    string
-	config  *Config
-	client  *githubClient
-	payload *Payload
-	ctx     context.Context
+	id        int
+	key       *PrivateKey
+	config    *Config
+	client    *githubClient
+	appClient *githubClient
+	clients   map[int]*githubClient
+	payload   *Payload
+	cache     *fileCache
+	store     Store
+	rand      *rand.Rand
+	clock     func() time.Time
+	mu        sync.Mutex
+	ctx       context.Context
+
+	reporter    ErrorReporter
+	breadcrumbs []Breadcrumb
+	baseURL     *url.URL
+	uploadURL   *url.URL
+	keyVersion  int
+}
+
+// NewBot creates a new bot instance for the app with the private key.
+func NewBot(id int, key *PrivateKey) *Bot {
+	return &Bot{
+		id:    id,
+		key:   key,
+		ctx:   context.Background(),
+		cache: newFileCache(5 * time.Minute),
+		store: NewMemoryStore(),
+	}
 }
 
-// NewBot creates a new bot instance.
-func NewBot(id int, cert string) *Bot {
-	return &Bot{id: id, cert: cert, ctx: context.Background()}
+// SetStore sets the store used to persist state between webhooks.
+func (b *Bot) SetStore(store Store) {
+	b.store = store
+}
+
+// SetBaseURLs sets the GitHub API and upload base URLs, like
+// `https://github.example.com/api/v3/` for GitHub Enterprise Server. The
+// upload URL is derived from the API URL when it's empty.
+func (b *Bot) SetBaseURLs(baseURL, uploadURL string) error {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return errors.Wrap(err, "parsing github url")
+	}
+
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+
+	if len(uploadURL) == 0 {
+		upload := *base
+
+		if base.Host == "api.github.com" {
+			upload.Host = "uploads.github.com"
+		} else if strings.HasSuffix(base.Path, "/api/v3/") {
+			upload.Path = strings.TrimSuffix(base.Path, "v3/") + "uploads/"
+		}
+
+		uploadURL = upload.String()
+	}
+
+	upload, err := url.Parse(uploadURL)
+	if err != nil {
+		return errors.Wrap(err, "parsing github upload url")
+	}
+
+	if !strings.HasSuffix(upload.Path, "/") {
+		upload.Path += "/"
+	}
+
+	b.baseURL = base
+	b.uploadURL = upload
+
+	return nil
+}
+
+// newGitHubClient creates a GitHub client for the bot base URLs.
+func (b *Bot) newGitHubClient(tr http.RoundTripper) (*github.Client, error) {
+	httpClient := &http.Client{Transport: tr}
+
+	if b.baseURL == nil {
+		return github.NewClient(httpClient), nil
+	}
+
+	return github.NewEnterpriseClient(b.baseURL.String(), b.uploadURL.String(), httpClient)
+}
+
+// apiURL returns the GitHub API base URL without a trailing slash as used by
+// ghinstallation, so installation tokens are created with the same API.
+func (b *Bot) apiURL() string {
+	if b.baseURL == nil {
+		return "https://api.github.com"
+	}
+
+	return strings.TrimSuffix(b.baseURL.String(), "/")
+}
+
+// now returns the current time from the bot clock.
+func (b *Bot) now() time.Time {
+	if b.clock == nil {
+		return time.Now()
+	}
+
+	return b.clock()
+}
+
+// getStore returns the store, defaults to a memory store.
+func (b *Bot) getStore() Store {
+	if b.store == nil {
+		b.store = NewMemoryStore()
+	}
+
+	return b.store
 }
 
 // validatePayload validates the payload from github.
@@ -57,14 +211,14 @@ func (b *Bot) validatePayload() error {
 
 	for _, user := range b.config.Ignore.Users {
 		if strings.ToLower(user) == strings.ToLower(b.payload.Sender.Login) {
-			return fmt.Errorf("User with login %s should be ignored", user)
+			return skipf("User with login %s should be ignored", user)
 		}
 	}
 
-	for _, label := range b.payload.Issue.Labels {
+	for _, label := range b.payload.Labels() {
 		for _, name := range b.config.Ignore.Labels {
 			if strings.ToLower(name) == strings.ToLower(label.Name) {
-				return fmt.Errorf("Issue or pull request with label %s should be ignored", name)
+				return skipf("Issue or pull request with label %s should be ignored", name)
 			}
 		}
 	}
@@ -91,39 +245,139 @@ func (b *Bot) item() (Item, error) {
 		return Item{}, errors.New("No config exists")
 	}
 
-	if b.payload.IsPullRequest() {
+	if b.payload.OnPullRequest() {
 		return b.config.PullRequest, nil
 	}
 
 	return b.config.Issue, nil
 }
 
-// createClient creates a new GitHub client.
+// createClient returns the GitHub client for the payload installation, the
+// client is resolved for each delivery since deliveries comes from different
+// installations.
 func (b *Bot) createClient() (*githubClient, error) {
 	if b.payload == nil {
 		return nil, errors.New("No payload exists")
 	}
 
-	if b.client != nil {
-		return b.client, nil
+	_, span := b.startSpan("github.client")
+	defer span.Finish()
+
+	client, err := b.installationClient(b.payload.Installation.ID)
+	span.SetError(err)
+
+	return client, err
+}
+
+// installationClient returns a GitHub client for the installation.
+func (b *Bot) installationClient(id int) (*githubClient, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rotateClients()
+
+	if client, ok := b.clients[id]; ok {
+		return client, nil
+	}
+
+	key, err := b.privateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	tr := &trace.Transport{Base: http.DefaultTransport}
+	itr, err := ghinstallation.New(tr, b.id, id, key)
+	if err != nil {
+		return nil, err
 	}
 
-	tr := http.DefaultTransport
-	itr, err := ghinstallation.NewKeyFromFile(tr, b.id, b.payload.Installation.ID, b.cert)
+	itr.BaseURL = b.apiURL()
+	client, err := b.newGitHubClient(itr)
 	if err != nil {
 		return nil, err
 	}
 
-	client := github.NewClient(&http.Client{Transport: itr})
+	if b.clients == nil {
+		b.clients = map[int]*githubClient{}
+	}
+
+	b.clients[id] = &githubClient{
+		Apps:          client.Apps,
+		Checks:        &checksService{client: client},
+		GraphQL:       &graphQLService{client: client},
+		Issues:        client.Issues,
+		Organizations: client.Organizations,
+		PullRequests:  client.PullRequests,
+		Reactions:     client.Reactions,
+		Repositories:  client.Repositories,
+	}
 
-	return &githubClient{
-		Issues:       client.Issues,
-		Repositories: client.Repositories,
-	}, nil
+	return b.clients[id], nil
 }
 
-// downloadConfig downloads the bot configuration from GitHub.
-func (b *Bot) downloadConfig() (*Config, error) {
+// rotateClients drops the cached clients when the private key has been
+// reloaded since they were created, the bot mutex must be held.
+func (b *Bot) rotateClients() {
+	if b.key == nil {
+		return
+	}
+
+	if _, version := b.key.PEM(); version != b.keyVersion {
+		b.clients = nil
+		b.appClient = nil
+		b.keyVersion = version
+	}
+}
+
+// privateKey returns the PEM data of the app private key.
+func (b *Bot) privateKey() ([]byte, error) {
+	if b.key == nil {
+		return nil, errors.New("No private key")
+	}
+
+	data, _ := b.key.PEM()
+
+	return data, nil
+}
+
+// createAppClient creates a new GitHub client authenticated as the app.
+func (b *Bot) createAppClient() (*githubClient, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rotateClients()
+
+	if b.appClient != nil {
+		return b.appClient, nil
+	}
+
+	key, err := b.privateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	tr := &trace.Transport{Base: http.DefaultTransport}
+	atr, err := ghinstallation.NewAppsTransport(tr, b.id, key)
+	if err != nil {
+		return nil, err
+	}
+
+	atr.BaseURL = b.apiURL()
+	client, err := b.newGitHubClient(atr)
+	if err != nil {
+		return nil, err
+	}
+
+	b.appClient = &githubClient{
+		Apps: client.Apps,
+	}
+
+	return b.appClient, nil
+}
+
+// downloadFile downloads a file from the repository default branch.
+// Downloaded files are cached when the bot has a file cache.
+func (b *Bot) downloadFile(path string) ([]byte, error) {
 	if b.payload == nil {
 		return nil, errors.New("No payload exists")
 	}
@@ -132,16 +386,56 @@ func (b *Bot) downloadConfig() (*Config, error) {
 		return nil, errors.New("No GitHub client")
 	}
 
-	buf, err := b.client.Repositories.DownloadContents(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, ".hello.yml", &github.RepositoryContentGetOptions{})
+	key := b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name + "/" + path
+
+	if b.cache != nil {
+		if data, ok := b.cache.get(key, b.now()); ok {
+			return data, nil
+		}
+	}
+
+	buf, err := b.client.Repositories.DownloadContents(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, path, &github.RepositoryContentGetOptions{})
 	if err != nil {
 		return nil, errors.Wrap(err, "downloading github file")
 	}
+	defer buf.Close()
 
 	data, err := ioutil.ReadAll(buf)
 	if err != nil {
 		return nil, errors.Wrap(err, "reading github file")
 	}
 
+	if b.cache != nil {
+		b.cache.set(key, data, b.now())
+	}
+
+	return data, nil
+}
+
+// downloadConfig downloads the bot configuration from GitHub.
+func (b *Bot) downloadConfig() (*Config, error) {
+	ctx, span := b.startSpan("config.download")
+	defer span.Finish()
+
+	var config *Config
+
+	err := b.withContext(ctx, func() error {
+		data, err := b.downloadFile(".hello.yml")
+		if err != nil {
+			return err
+		}
+
+		config, err = parseConfig(data)
+		return err
+	})
+
+	span.SetError(err)
+
+	return config, err
+}
+
+// parseConfig parses the `.hello.yml` file.
+func parseConfig(data []byte) (*Config, error) {
 	var config *Config
 
 	if err := yaml.Unmarshal(data, &config); err != nil {
@@ -151,25 +445,73 @@ func (b *Bot) downloadConfig() (*Config, error) {
 	return config, nil
 }
 
-// SayHello will take a http request, decode the request body and write a hello comment.
-func (b *Bot) SayHello(r *http.Request) error {
-	var err error
+// decodePayload decodes the payload from the http request body.
+func decodePayload(r *http.Request) (*Payload, error) {
 	var payload *Payload
 
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return nil, errors.Wrap(err, "unmarshal payload")
+	}
+
+	if payload == nil {
+		return nil, errors.New("No payload exists")
+	}
+
+	return payload, nil
+}
+
+// unmarshalPayload unmarshals the request body into a payload of the event type.
+func (b *Bot) unmarshalPayload(body []byte, payload interface{}) error {
+	_, span := b.startSpan("payload.decode")
+	defer span.Finish()
+
+	if err := json.Unmarshal(body, payload); err != nil {
+		span.SetError(err)
 		return errors.Wrap(err, "unmarshal payload")
 	}
 
+	return nil
+}
+
+// withContext runs the function with the context as the bot context, used
+// to make spans started by the function children of a span.
+func (b *Bot) withContext(ctx context.Context, fn func() error) error {
+	parent := b.ctx
+	b.ctx = ctx
+	defer func() {
+		b.ctx = parent
+	}()
+
+	return fn()
+}
+
+// rule traces the evaluation of a configured rule, like a check or a label rule.
+func (b *Bot) rule(name string, fn func() error) error {
+	ctx, span := b.startSpan("rule." + name)
+	defer span.Finish()
+
+	err := b.withContext(ctx, fn)
+	span.SetError(err)
+
+	return err
+}
+
+// prepare creates the GitHub client and downloads the config for the payload repository.
+func (b *Bot) prepare(payload *Payload) error {
+	var err error
+
 	b.payload = payload
 
-	// Only issues or pull requests with "opened" action is allowed.
-	if b.payload.Action != "opened" {
-		return errors.New("Only opened action is handled")
+	// Spans of webhook deliveries gets the repository as an attribute.
+	if span := trace.FromContext(b.ctx); span != nil {
+		repository := payload.Repository.Owner.Login + "/" + payload.Repository.Name
+		span.SetAttribute("github.repository", repository)
+		b.ctx = trace.WithAttributes(b.ctx, "github.repository", repository)
 	}
 
 	// Only open GitHub projects is allowed.
 	if b.payload.Repository.Private {
-		return errors.New("Only public repository can be used")
+		return skip("Only public repository can be used")
 	}
 
 	// Create GitHub client.
@@ -184,6 +526,241 @@ func (b *Bot) SayHello(r *http.Request) error {
 		return err
 	}
 
+	// Config files that are empty or only has comments has no config.
+	if b.config == nil {
+		return skip("No config")
+	}
+
+	return nil
+}
+
+// Handle will take a http request, decode the request body and handle the GitHub event.
+func (b *Bot) Handle(r *http.Request) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading payload")
+	}
+
+	return b.HandleEvent(r.Header.Get("X-GitHub-Event"), r.Header.Get("X-GitHub-Delivery"), body)
+}
+
+// HandleEvent handles the GitHub event payload from the webhook delivery.
+func (b *Bot) HandleEvent(event, delivery string, body []byte) error {
+	return b.handleEvent(b.ctx, event, delivery, body, false)
+}
+
+// handleEvent handles the webhook delivery in a span that is a child of the
+// span in the context, like the span of the received webhook request.
+// Redeliveries are handled even if the delivery has been handled before.
+func (b *Bot) handleEvent(ctx context.Context, event, delivery string, body []byte, redelivery bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// The client is resolved again from the payload installation.
+	b.client = nil
+	b.breadcrumbs = []Breadcrumb{{Time: b.now(), Category: "webhook", Message: event}}
+
+	ctx = trace.WithAttributes(ctx, "github.event", event, "github.delivery", delivery)
+	ctx, span := trace.Start(ctx, "webhook.handle")
+
+	err := b.withContext(ctx, func() error {
+		return b.handleDelivery(event, delivery, body, redelivery)
+	})
+
+	span.SetError(err)
+	span.Finish()
+
+	b.report(err, event, delivery, body)
+
+	deliveriesProcessed.Add(1)
+
+	deliveryOutcomes.Add(outcome(err), 1)
+
+	return err
+}
+
+// handleDelivery handles the webhook delivery once and records the outcome.
+func (b *Bot) handleDelivery(event, delivery string, body []byte, redelivery bool) error {
+	if len(delivery) == 0 {
+		return b.handleRecovered(event, body)
+	}
+
+	// GitHub redelivers webhooks, each delivery is only handled once.
+	if !redelivery {
+		delivered, err := b.delivered(delivery)
+		if err != nil {
+			return err
+		}
+
+		if delivered {
+			return &skipError{message: "Delivery " + delivery + " already handled", duplicate: true}
+		}
+	}
+
+	err := b.handleRecovered(event, body)
+
+	if recordErr := b.recordDelivery(delivery, event, body, err); recordErr != nil && err == nil {
+		return recordErr
+	}
+
+	return err
+}
+
+// handleRecovered handles the GitHub event payload and returns a panic as an
+// error, so the delivery is recorded as failed and reported like other errors.
+func (b *Bot) handleRecovered(event string, body []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("Panic handling %s event: %v", event, r)
+		}
+	}()
+
+	return b.handle(event, body)
+}
+
+// handle handles the GitHub event payload.
+func (b *Bot) handle(event string, body []byte) error {
+	switch event {
+	case "discussion":
+		var payload DiscussionPayload
+		if err := b.unmarshalPayload(body, &payload); err != nil {
+			return err
+		}
+
+		return b.greetDiscussion(&payload)
+	case "star", "fork":
+		var payload MilestonePayload
+		if err := b.unmarshalPayload(body, &payload); err != nil {
+			return err
+		}
+
+		return b.milestone(event, &payload)
+	case "release":
+		var payload ReleasePayload
+		if err := b.unmarshalPayload(body, &payload); err != nil {
+			return err
+		}
+
+		return b.release(&payload)
+	case "issue_comment":
+		var payload Payload
+		if err := b.unmarshalPayload(body, &payload); err != nil {
+			return err
+		}
+
+		return b.handleComment(&payload)
+	case "issues", "pull_request", "pull_request_review", "pull_request_review_comment":
+		var payload Payload
+		if err := b.unmarshalPayload(body, &payload); err != nil {
+			return err
+		}
+
+		// A failing check doesn't stop the greeting or the stale handling,
+		// the errors from all steps are combined.
+		var steps []error
+		if payload.IsPullRequest() {
+			switch payload.Action {
+			case "opened", "synchronize", "reopened", "edited":
+				steps = append(steps, b.checkPullRequest(&payload))
+			}
+		}
+
+		if payload.Action == "opened" {
+			return joinErrors(append(steps, b.sayHello(&payload))...)
+		}
+
+		if payload.Action == "closed" && payload.PullRequest.Merged {
+			return b.merged(&payload)
+		}
+
+		return joinErrors(append(steps, b.handleActivity(&payload))...)
+	default:
+		// Events like installation, check_run and push have no issue or
+		// pull request to handle.
+		return skipf("Unhandled event %s", event)
+	}
+}
+
+// checkPullRequest runs the enabled checks, like the CLA, DCO and
+// Conventional Commits checks, on the pull request.
+func (b *Bot) checkPullRequest(payload *Payload) error {
+	if err := b.prepare(payload); err != nil {
+		return err
+	}
+
+	number := b.payload.PullRequest.Number
+	sha := b.payload.PullRequest.Head.SHA
+
+	// Only the title is checked again when the pull request is edited.
+	if b.payload.Action != "edited" {
+		if b.config.CLA.enabled() {
+			if err := b.rule("cla", func() error { return b.reportCLA(number, sha) }); err != nil {
+				return err
+			}
+		}
+
+		if b.config.DCO.Enabled {
+			if err := b.rule("dco", func() error { return b.checkDCO(number, sha) }); err != nil {
+				return err
+			}
+		}
+	}
+
+	if b.config.Semantic.Enabled {
+		if err := b.rule("semantic", func() error { return b.checkSemantic(number, sha) }); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handleComment handles new comments on issues and pull requests.
+func (b *Bot) handleComment(payload *Payload) error {
+	// The results of all steps are combined, so comments that signed the
+	// CLA or ran commands are not skipped when the stale handling is.
+	var steps []error
+
+	if payload.Action == "created" && payload.Sender.Type != "Bot" {
+		if err := b.prepare(payload); err != nil {
+			return err
+		}
+
+		if b.config.CLA.enabled() {
+			steps = append(steps, b.rule("cla", b.signCLA))
+		}
+
+		if len(b.config.Commands) > 0 {
+			steps = append(steps, b.rule("commands", b.runCommands))
+		}
+	}
+
+	return joinErrors(append(steps, b.handleActivity(payload))...)
+}
+
+// SayHello will take a http request, decode the request body and write a hello comment.
+func (b *Bot) SayHello(r *http.Request) error {
+	payload, err := decodePayload(r)
+	if err != nil {
+		return err
+	}
+
+	return b.sayHello(payload)
+}
+
+// sayHello writes a hello comment to the issue or pull request in the payload.
+func (b *Bot) sayHello(payload *Payload) error {
+	// Only issues or pull requests with "opened" action is allowed.
+	if payload.Action != "opened" {
+		b.payload = payload
+		return skip("Only opened action is handled")
+	}
+
+	if err := b.prepare(payload); err != nil {
+		return err
+	}
+
 	// Validate payload with config values.
 	if err := b.validatePayload(); err != nil {
 		return errors.Wrap(err, "validate payload")
@@ -195,7 +772,10 @@ func (b *Bot) SayHello(r *http.Request) error {
 		return err
 	}
 	if item.Disabled {
-		return errors.New("Item disabled")
+		return skip("Item disabled")
+	}
+	if len(item.Reaction) > 0 && !validReaction(item.Reaction) {
+		return errors.Errorf("Unknown reaction %s", item.Reaction)
 	}
 
 	number, err := b.number()
@@ -207,33 +787,118 @@ func (b *Bot) SayHello(r *http.Request) error {
 		return errors.New("No GitHub client")
 	}
 
-	// Create GitHub comment.
-	_, _, err = b.client.Issues.CreateComment(
-		b.ctx,
-		b.payload.Repository.Owner.Login,
-		b.payload.Repository.Name,
-		number,
-		&github.IssueComment{
-			Body: github.String(strings.Replace(item.Message, "@{author}", b.payload.Sender.Login, -1)),
-		},
-	)
-
-	if err := errors.Wrap(err, "github create comment"); err != nil {
+	message, labels, err := b.greeting(item)
+	if err != nil {
 		return err
 	}
 
+	// Add label for the detected language.
+	if item.LanguageLabels {
+		b.rule("language", func() error {
+			if language := b.language(); len(language) > 0 {
+				labels = appendUnique(labels, "lang:"+language)
+			}
+
+			return nil
+		})
+	}
+
+	// Add labels and messages from path rules that matches changed files.
+	if b.payload.IsPullRequest() && len(item.Paths) > 0 {
+		var pathLabels, pathMessages []string
+		err := b.rule("paths", func() (err error) {
+			pathLabels, pathMessages, err = b.matchPaths(item.Paths, number)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		labels = appendUnique(labels, pathLabels...)
+
+		for _, m := range pathMessages {
+			message = strings.TrimSpace(message) + "\n\n" + m
+		}
+	}
+
+	// Create GitHub comment if there is a message, items can be reaction only.
+	if len(strings.TrimSpace(message)) > 0 {
+		_, _, err = b.client.Issues.CreateComment(
+			b.ctx,
+			b.payload.Repository.Owner.Login,
+			b.payload.Repository.Name,
+			number,
+			&github.IssueComment{
+				Body: github.String(renderMessage(message, b.payload.Sender.Login)),
+			},
+		)
+
+		if err := errors.Wrap(err, "github create comment"); err != nil {
+			return err
+		}
+	}
+
+	// Add reaction to GitHub issue if any.
+	if len(item.Reaction) > 0 {
+		_, _, err = b.client.Reactions.CreateIssueReaction(
+			b.ctx,
+			b.payload.Repository.Owner.Login,
+			b.payload.Repository.Name,
+			number,
+			item.Reaction,
+		)
+
+		if err != nil {
+			return errors.Wrap(err, "github create issue reaction")
+		}
+	}
+
 	// Add labels to GitHub issue if any.
-	if len(item.Labels) > 0 {
+	if len(labels) > 0 {
 		_, _, err = b.client.Issues.AddLabelsToIssue(
 			b.ctx,
 			b.payload.Repository.Owner.Login,
 			b.payload.Repository.Name,
 			number,
-			item.Labels,
+			labels,
 		)
 
-		return errors.Wrap(err, "github add labels to issue")
+		if err != nil {
+			return errors.Wrap(err, "github add labels to issue")
+		}
 	}
 
-	return err
+	// Request reviewers for pull requests.
+	if b.payload.IsPullRequest() && !item.Reviewers.empty() {
+		if err := b.rule("reviewers", func() error { return b.requestReviewers(item.Reviewers, number) }); err != nil {
+			return err
+		}
+	}
+
+	// Add assignees to issue or pull request.
+	if !item.Assignees.empty() {
+		if err := b.rule("assignees", func() error { return b.addAssignees(item.Assignees, number) }); err != nil {
+			return err
+		}
+	}
+
+	if err := b.recordGreeted(b.payload.Sender.Login); err != nil {
+		return err
+	}
+
+	return b.record(number, "greeted")
+}
+
+// renderMessage replaces the placeholders in the message.
+func renderMessage(message, author string) string {
+	return renderPlaceholders(message, map[string]string{"author": author})
+}
+
+// renderPlaceholders replaces `@{name}` placeholders in the message.
+func renderPlaceholders(message string, placeholders map[string]string) string {
+	for name, value := range placeholders {
+		message = strings.Replace(message, "@{"+name+"}", value, -1)
+	}
+
+	return message
 }
@@ -6,9 +6,13 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
 )
 
 type ClosingBuffer struct {
@@ -19,30 +23,30 @@ func (cb *ClosingBuffer) Close() error {
 	return nil
 }
 
-var issueOpenedRequest = &http.Request{
-	Body: &ClosingBuffer{bytes.NewBufferString(`
-		{
-			"action": "opened",
-			"issue": {
-				"number": 1234,
-				"labels": []
-			},
-			"repository": {
-				"default_branch": "master",
-				"name": "Fredrik",
-				"owner": {
-					"login": "test"
-				}
-			},
-			"sender": {
+const issueOpenedPayload = `
+	{
+		"action": "opened",
+		"issue": {
+			"number": 1234,
+			"labels": []
+		},
+		"repository": {
+			"default_branch": "master",
+			"name": "Fredrik",
+			"owner": {
 				"login": "test"
-			},
-			"installation": {
-				"id": 1234
 			}
+		},
+		"sender": {
+			"login": "test"
+		},
+		"installation": {
+			"id": 1234
 		}
-	`)},
-}
+	}
+`
+
+var issueOpenedRequest = newRequest(issueOpenedPayload)
 
 var issueCreatedRequest = &http.Request{
 	Body: &ClosingBuffer{bytes.NewBufferString(`
@@ -69,33 +73,377 @@ var issueCreatedRequest = &http.Request{
 	`)},
 }
 
+const pullRequestOpenedPayload = `
+	{
+		"action": "opened",
+		"pull_request": {
+			"number": 1234
+		},
+		"repository": {
+			"default_branch": "master",
+			"name": "Fredrik",
+			"owner": {
+				"login": "test"
+			}
+		},
+		"sender": {
+			"login": "octocat"
+		},
+		"installation": {
+			"id": 1234
+		}
+	}
+`
+
+func newRequest(body string) *http.Request {
+	return &http.Request{Body: &ClosingBuffer{bytes.NewBufferString(body)}}
+}
+
 type githubIssues struct {
+	comments  []string
+	labels    []string
+	assignees [][]string
+	assigned  map[string]int
+	issues    []*github.Issue
+	edits     []*github.IssueRequest
+	removed   []string
+	locked    []int
+	created   []*github.IssueRequest
+	commented []int
+	failOn    int
+	perPage   int
+}
+
+// touch moves the issue last when issues are paged, like changed issues
+// when they are sorted by last update.
+func (g *githubIssues) touch(number int) {
+	if g.perPage == 0 {
+		return
+	}
+
+	for i, issue := range g.issues {
+		if issue.GetNumber() == number {
+			g.issues = append(append(g.issues[:i:i], g.issues[i+1:]...), issue)
+			return
+		}
+	}
+}
+
+func (g *githubIssues) Create(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	g.created = append(g.created, issue)
+	return nil, nil, nil
+}
+
+func (g *githubIssues) AddAssignees(ctx context.Context, owner string, repo string, number int, assignees []string) (*github.Issue, *github.Response, error) {
+	g.assignees = append(g.assignees, assignees)
+	return nil, nil, nil
+}
+
+func (g *githubIssues) AddLabelsToIssue(ctx context.Context, owner string, repo string, number int, labels []string) ([]*github.Label, *github.Response, error) {
+	g.labels = append(g.labels, labels...)
+	g.touch(number)
+	return nil, nil, nil
+}
+func (g *githubIssues) CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	if g.failOn != 0 && number == g.failOn {
+		return nil, nil, errors.New("Comment failed")
+	}
+
+	g.comments = append(g.comments, comment.GetBody())
+	g.commented = append(g.commented, number)
+	return nil, nil, nil
+}
+func (g *githubIssues) Edit(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	g.edits = append(g.edits, issue)
+	g.touch(number)
+	return nil, nil, nil
+}
+func (g *githubIssues) ListByRepo(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	if len(opts.Assignee) > 0 {
+		// Assigned issues are paged like the GitHub API.
+		page := opts.Page
+		if page == 0 {
+			page = 1
+		}
+
+		remaining := g.assigned[opts.Assignee] - (page-1)*opts.PerPage
+		if remaining > opts.PerPage {
+			return make([]*github.Issue, opts.PerPage), &github.Response{NextPage: page + 1}, nil
+		}
+
+		return make([]*github.Issue, remaining), &github.Response{}, nil
+	}
+
+	var issues []*github.Issue
+	for _, issue := range g.issues {
+		if opts.State == "all" || issue.GetState() == opts.State {
+			issues = append(issues, issue)
+		}
+	}
+
+	if g.perPage > 0 {
+		page := opts.Page
+		if page == 0 {
+			page = 1
+		}
+
+		start := (page - 1) * g.perPage
+		if start+g.perPage < len(issues) {
+			return issues[start : start+g.perPage], &github.Response{NextPage: page + 1}, nil
+		}
+
+		if start > len(issues) {
+			start = len(issues)
+		}
+
+		return issues[start:], &github.Response{}, nil
+	}
+
+	return issues, &github.Response{}, nil
+}
+func (g *githubIssues) Lock(ctx context.Context, owner string, repo string, number int) (*github.Response, error) {
+	g.locked = append(g.locked, number)
+	g.touch(number)
+	return nil, nil
+}
+func (g *githubIssues) RemoveLabelForIssue(ctx context.Context, owner string, repo string, number int, label string) (*github.Response, error) {
+	g.removed = append(g.removed, label)
+	return nil, nil
+}
+
+type githubChecks struct {
+	runs []*CheckRun
+}
+
+func (g *githubChecks) CreateCheckRun(ctx context.Context, owner string, repo string, run *CheckRun) (*CheckRun, *github.Response, error) {
+	g.runs = append(g.runs, run)
+	return run, nil, nil
+}
+
+type githubOrganizations struct {
+	members []string
+	teams   map[string][]string
+}
+
+func (g *githubOrganizations) IsMember(ctx context.Context, org string, user string) (bool, *github.Response, error) {
+	for _, member := range g.members {
+		if member == user {
+			return true, nil, nil
+		}
+	}
+
+	return false, nil, nil
+}
+
+func (g *githubOrganizations) ListTeamMembers(ctx context.Context, team int64, opts *github.OrganizationListTeamMembersOptions) ([]*github.User, *github.Response, error) {
+	var users []*github.User
+	for _, login := range g.teams[g.teamSlugs()[team-1]] {
+		users = append(users, &github.User{Login: github.String(login)})
+	}
+
+	return users, &github.Response{}, nil
+}
+
+func (g *githubOrganizations) ListTeams(ctx context.Context, org string, opts *github.ListOptions) ([]*github.Team, *github.Response, error) {
+	var teams []*github.Team
+	for i, slug := range g.teamSlugs() {
+		teams = append(teams, &github.Team{ID: github.Int64(int64(i + 1)), Slug: github.String(slug)})
+	}
+
+	return teams, &github.Response{}, nil
+}
+
+// teamSlugs returns the sorted team slugs, team ids are their positions.
+func (g *githubOrganizations) teamSlugs() []string {
+	var slugs []string
+	for slug := range g.teams {
+		slugs = append(slugs, slug)
+	}
+
+	sort.Strings(slugs)
+
+	return slugs
+}
+
+type githubGraphQL struct {
+	variables []map[string]interface{}
+}
+
+func (g *githubGraphQL) Query(ctx context.Context, query string, variables map[string]interface{}, v interface{}) error {
+	g.variables = append(g.variables, variables)
+	return nil
+}
+
+type githubPullRequests struct {
+	commits   []string
+	authors   []string
+	list      []*github.RepositoryCommit
+	head      string
+	files     [][]string
+	reviewers []github.ReviewersRequest
+}
+
+func (g *githubPullRequests) ListCommits(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	var commits []*github.RepositoryCommit
+	for _, message := range g.commits {
+		commits = append(commits, &github.RepositoryCommit{Commit: &github.Commit{Message: github.String(message)}})
+	}
+
+	for _, login := range g.authors {
+		commits = append(commits, &github.RepositoryCommit{Author: &github.User{Login: github.String(login)}})
+	}
+
+	commits = append(commits, g.list...)
+
+	return commits, &github.Response{}, nil
+}
+
+func (g *githubPullRequests) Get(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	return &github.PullRequest{Head: &github.PullRequestBranch{SHA: github.String(g.head)}}, nil, nil
+}
+
+func (g *githubPullRequests) RequestReviewers(ctx context.Context, owner string, repo string, number int, reviewers github.ReviewersRequest) (*github.PullRequest, *github.Response, error) {
+	g.reviewers = append(g.reviewers, reviewers)
+	return nil, nil, nil
+}
+
+func (g *githubPullRequests) ListFiles(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+	page := opts.Page
+	if page > 0 {
+		page--
+	}
+
+	res := &github.Response{}
+	if page+1 < len(g.files) {
+		res.NextPage = page + 2
+	}
+
+	var files []*github.CommitFile
+	if page < len(g.files) {
+		for _, name := range g.files[page] {
+			files = append(files, &github.CommitFile{Filename: github.String(name)})
+		}
+	}
+
+	return files, res, nil
 }
 
-func (g *githubIssues) AddLabelsToIssue(context.Context, string, string, int, []string) ([]*github.Label, *github.Response, error) {
+type githubReactions struct {
+	reactions []string
+	comments  []string
+}
+
+func (g *githubReactions) CreateIssueCommentReaction(ctx context.Context, owner string, repo string, id int64, content string) (*github.Reaction, *github.Response, error) {
+	g.comments = append(g.comments, content)
 	return nil, nil, nil
 }
-func (g *githubIssues) CreateComment(context.Context, string, string, int, *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+
+func (g *githubReactions) CreateIssueReaction(ctx context.Context, owner string, repo string, number int, content string) (*github.Reaction, *github.Response, error) {
+	g.reactions = append(g.reactions, content)
 	return nil, nil, nil
 }
 
 type githubRepositories struct {
+	config    string
+	files     map[string]string
+	downloads int
+	compares  map[string]string
+	refs      []string
+	statuses  []*github.RepoStatus
+	members   []string
+}
+
+func (g *githubRepositories) CreateStatus(ctx context.Context, owner string, repo string, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+	g.refs = append(g.refs, ref)
+	g.statuses = append(g.statuses, status)
+	return status, nil, nil
+}
+
+func (g *githubRepositories) GetPermissionLevel(ctx context.Context, owner string, repo string, user string) (*github.RepositoryPermissionLevel, *github.Response, error) {
+	permission := "read"
+	for _, member := range g.members {
+		if member == user {
+			permission = "write"
+		}
+	}
+
+	return &github.RepositoryPermissionLevel{Permission: github.String(permission)}, nil, nil
+}
+
+func (g *githubRepositories) IsCollaborator(ctx context.Context, owner string, repo string, user string) (bool, *github.Response, error) {
+	for _, member := range g.members {
+		if member == user {
+			return true, nil, nil
+		}
+	}
+
+	return false, nil, nil
+}
+
+func (g *githubRepositories) CompareCommits(ctx context.Context, owner string, repo string, base string, head string) (*github.CommitsComparison, *github.Response, error) {
+	status, ok := g.compares[base+"..."+head]
+	if !ok {
+		status = "behind"
+	}
+
+	return &github.CommitsComparison{Status: github.String(status)}, nil, nil
 }
 
 func (g *githubRepositories) DownloadContents(ctx context.Context, owner string, name string, file string, opts *github.RepositoryContentGetOptions) (io.ReadCloser, error) {
+	g.downloads++
+
+	if file != ".hello.yml" {
+		if content, ok := g.files[file]; ok {
+			return &ClosingBuffer{bytes.NewBufferString(content)}, nil
+		}
+
+		return nil, errors.New("File not found")
+	}
+
+	if len(g.config) > 0 {
+		return &ClosingBuffer{bytes.NewBufferString(g.config)}, nil
+	}
+
 	dat, _ := ioutil.ReadFile("../.hello.yml")
 	return &ClosingBuffer{bytes.NewBufferString(string(dat))}, nil
 }
 
+type githubApps struct {
+	installations []*github.Installation
+	repos         []*github.Repository
+	listed        int
+}
+
+func (g *githubApps) ListInstallations(ctx context.Context, opts *github.ListOptions) ([]*github.Installation, *github.Response, error) {
+	g.listed++
+	return g.installations, &github.Response{}, nil
+}
+
+func (g *githubApps) ListRepos(ctx context.Context, opts *github.ListOptions) ([]*github.Repository, *github.Response, error) {
+	return g.repos, &github.Response{}, nil
+}
+
 func newClient(httpClient *http.Client) *githubClient {
 	return &githubClient{
-		Issues:       &githubIssues{},
-		Repositories: &githubRepositories{},
+		Apps:          &githubApps{},
+		Checks:        &githubChecks{},
+		GraphQL:       &githubGraphQL{},
+		Issues:        &githubIssues{},
+		Organizations: &githubOrganizations{},
+		PullRequests:  &githubPullRequests{},
+		Reactions:     &githubReactions{},
+		Repositories:  &githubRepositories{},
 	}
 }
 
+// installations returns the clients by installation, the test payloads are
+// from installation 1234 or without an installation.
+func installations(client *githubClient) map[int]*githubClient {
+	return map[int]*githubClient{0: client, 1234: client}
+}
+
 func TestBot(t *testing.T) {
-	b := &Bot{id: 1234, cert: "", ctx: context.Background(), client: newClient(nil)}
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(newClient(nil))}
 
 	// Test issue with a opened action.
 	if err := b.SayHello(issueOpenedRequest); err != nil {
@@ -108,10 +456,138 @@ func TestBot(t *testing.T) {
 	}
 }
 
+func TestBotReaction(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+issue:
+  reaction: heart
+  labels:
+    - triage
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := client.Issues.(*githubIssues)
+
+	if len(issues.comments) != 0 {
+		t.Fatalf("Expected no comments, got %q", issues.comments)
+	}
+
+	if len(issues.labels) != 1 || issues.labels[0] != "triage" {
+		t.Fatalf("Expected triage label, got %v", issues.labels)
+	}
+
+	if reactions := client.Reactions.(*githubReactions).reactions; len(reactions) != 1 || reactions[0] != "heart" {
+		t.Fatalf("Expected heart reaction, got %v", reactions)
+	}
+}
+
+func TestBotUnknownReaction(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+issue:
+  reaction: smile
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err == nil {
+		t.Fatal("Expected error for unknown reaction")
+	}
+}
+
 func TestBotGitHubClient(t *testing.T) {
-	b := &Bot{id: 1234, cert: "", ctx: context.Background()}
+	b := &Bot{id: 1234, ctx: context.Background()}
 
 	if err := b.SayHello(issueOpenedRequest); err == nil {
 		t.Fatal("Should not work without a GitHub client")
 	}
 }
+
+func TestBotInstallationClients(t *testing.T) {
+	first := newClient(nil)
+	second := newClient(nil)
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: map[int]*githubClient{1234: first, 222: second}}
+
+	if err := b.Handle(newEventRequest("issues", issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	payload := strings.Replace(issueOpenedPayload, `"id": 1234`, `"id": 222`, 1)
+	if err := b.Handle(newEventRequest("issues", payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, client := range map[int]*githubClient{1234: first, 222: second} {
+		if comments := client.Issues.(*githubIssues).comments; len(comments) != 1 {
+			t.Fatalf("Expected one comment with installation %d client, got %v", id, comments)
+		}
+	}
+}
+
+func TestBotPullRequestSteps(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: staleConfig + `
+pull_request:
+  message: Hello @{author}
+semantic:
+  enabled: true
+  commits: true
+`}
+
+	// Listing commits fails so the semantic check fails.
+	client.PullRequests = nil
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	pullRequest := func(action string) error {
+		return b.Handle(newEventRequest("pull_request", `
+			{
+				"action": "`+action+`",
+				"pull_request": {
+					"number": 14,
+					"title": "fix: handle empty config",
+					"labels": [{"name": "stale"}],
+					"head": {
+						"sha": "abc123"
+					}
+				},
+				"repository": {
+					"name": "hellobot",
+					"owner": {
+						"login": "frozzare"
+					}
+				},
+				"sender": {
+					"login": "octocat",
+					"type": "User"
+				}
+			}
+		`))
+	}
+
+	// The hello message is posted even if a check fails.
+	if err := pullRequest("opened"); err == nil {
+		t.Fatal("Expected check error")
+	}
+
+	issues := client.Issues.(*githubIssues)
+
+	if !reflect.DeepEqual(issues.comments, []string{"Hello octocat"}) {
+		t.Fatalf("Expected hello message, got %v", issues.comments)
+	}
+
+	// New commits are activity that removes the stale label.
+	if err := pullRequest("synchronize"); err == nil {
+		t.Fatal("Expected check error")
+	}
+
+	if !reflect.DeepEqual(issues.removed, []string{"stale"}) {
+		t.Fatalf("Expected stale label to be removed, got %v", issues.removed)
+	}
+}
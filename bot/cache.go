@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"sync"
+	"time"
+)
+
+// fileCache caches files downloaded from repositories, like the config file.
+type fileCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	items map[string]cacheItem
+}
+
+// cacheItem represents a cached file.
+type cacheItem struct {
+	data    []byte
+	expires time.Time
+}
+
+// newFileCache creates a new file cache where files expires after the ttl.
+func newFileCache(ttl time.Duration) *fileCache {
+	return &fileCache{ttl: ttl, items: map[string]cacheItem{}}
+}
+
+// get returns the cached file if it exists and has not expired.
+func (c *fileCache) get(key string, now time.Time) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok || !now.Before(item.expires) {
+		return nil, false
+	}
+
+	return item.data, true
+}
+
+// set caches the file and removes expired files.
+func (c *fileCache) set(key string, data []byte, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, item := range c.items {
+		if !now.Before(item.expires) {
+			delete(c.items, k)
+		}
+	}
+
+	c.items[key] = cacheItem{data: data, expires: now.Add(c.ttl)}
+}
+
+// len returns the number of cached files, including expired files not removed yet.
+func (c *fileCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// checksPreview is the media type required for the checks API.
+const checksPreview = "application/vnd.github.antiope-preview+json"
+
+// CheckRun represents a check run on a commit.
+type CheckRun struct {
+	ID          int64          `json:"id,omitempty"`
+	Name        string         `json:"name"`
+	HeadSHA     string         `json:"head_sha"`
+	DetailsURL  string         `json:"details_url,omitempty"`
+	Status      string         `json:"status,omitempty"`
+	Conclusion  string         `json:"conclusion,omitempty"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	Output      CheckRunOutput `json:"output"`
+}
+
+// CheckRunOutput represents the output of a check run.
+type CheckRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+	Text    string `json:"text,omitempty"`
+}
+
+// checksService creates check runs, the vendored GitHub client
+// has no support for the checks API.
+type checksService struct {
+	client *github.Client
+}
+
+// CreateCheckRun creates a check run for a commit in the repository.
+func (s *checksService) CreateCheckRun(ctx context.Context, owner, repo string, run *CheckRun) (*CheckRun, *github.Response, error) {
+	req, err := s.client.NewRequest("POST", fmt.Sprintf("repos/%s/%s/check-runs", owner, repo), run)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set("Accept", checksPreview)
+
+	created := new(CheckRun)
+	res, err := s.client.Do(ctx, req, created)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return created, res, nil
+}
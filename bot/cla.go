@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// reportCLA reports the CLA commit status for the pull request commit and
+// comments when there are contributors that have not been asked to sign yet.
+func (b *Bot) reportCLA(number int, sha string) error {
+	cla := b.config.CLA
+
+	unsigned, err := b.unsignedAuthors(number)
+	if err != nil {
+		return err
+	}
+
+	status := &github.RepoStatus{
+		State:       github.String("success"),
+		Description: github.String("All contributors have signed the CLA"),
+		Context:     github.String(cla.context()),
+		TargetURL:   github.String(cla.Document),
+	}
+
+	if len(unsigned) > 0 {
+		status.State = github.String("failure")
+		status.Description = github.String("All contributors must sign the CLA")
+	}
+
+	_, _, err = b.client.Repositories.CreateStatus(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, sha, status)
+	if err != nil {
+		return errors.Wrap(err, "github create status")
+	}
+
+	if len(unsigned) == 0 {
+		return nil
+	}
+
+	// Only comment when the unsigned contributors changes, not on every push.
+	key := "cla/" + b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name + "/pulls/" + strconv.Itoa(number)
+	users := strings.Join(unsigned, ", ")
+
+	notified, err := b.getStore().Get(key)
+	if err != nil {
+		return errors.Wrap(err, "get cla notified")
+	}
+
+	if string(notified) == users {
+		return nil
+	}
+
+	message := renderPlaceholders(cla.message(), map[string]string{
+		"users":    users,
+		"document": cla.Document,
+		"phrase":   cla.phrase(),
+	})
+
+	if err := b.comment(number, message); err != nil {
+		return err
+	}
+
+	return errors.Wrap(b.getStore().Set(key, []byte(users)), "set cla notified")
+}
+
+// unsignedAuthors returns the commit authors of the pull request that must
+// sign the CLA. Authors without a GitHub account are listed by name.
+func (b *Bot) unsignedAuthors(number int) ([]string, error) {
+	commits, err := b.listCommits(number)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := b.signedFile()
+	if err != nil {
+		return nil, err
+	}
+
+	checked := map[string]bool{}
+	var unsigned []string
+
+	for _, commit := range commits {
+		author := commit.GetAuthor()
+		login := author.GetLogin()
+
+		if len(login) == 0 {
+			name := commit.GetCommit().GetAuthor().GetName()
+			if !checked[name] {
+				unsigned = append(unsigned, name)
+			}
+
+			checked[name] = true
+			continue
+		}
+
+		if checked[login] || author.GetType() == "Bot" {
+			continue
+		}
+
+		checked[login] = true
+
+		ok, err := b.hasSignedCLA(login, signed)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			unsigned = append(unsigned, "@"+login)
+		}
+	}
+
+	sort.Strings(unsigned)
+
+	return unsigned, nil
+}
+
+// hasSignedCLA returns true when the user is allowlisted, a collaborator
+// on the repository or has signed the CLA.
+func (b *Bot) hasSignedCLA(login string, signed []string) (bool, error) {
+	for _, user := range append(b.config.CLA.Allowlist, signed...) {
+		if strings.ToLower(user) == strings.ToLower(login) {
+			return true, nil
+		}
+	}
+
+	buf, err := b.getStore().Get(b.signatureKey(login))
+	if err != nil {
+		return false, errors.Wrap(err, "get cla signature")
+	}
+
+	if buf != nil {
+		return true, nil
+	}
+
+	collaborator, _, err := b.client.Repositories.IsCollaborator(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, login)
+	if err != nil {
+		return false, errors.Wrap(err, "github is collaborator")
+	}
+
+	return collaborator, nil
+}
+
+// signedFile returns the users listed in the signed file in the repository,
+// one user per line.
+func (b *Bot) signedFile() ([]string, error) {
+	path := b.config.CLA.SignedFile
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	data, err := b.downloadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		users = append(users, strings.TrimPrefix(strings.Fields(line)[0], "@"))
+	}
+
+	return users, nil
+}
+
+// signatureKey returns the store key for the user signature.
+func (b *Bot) signatureKey(login string) string {
+	return "cla/" + b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name + "/signatures/" + strings.ToLower(login)
+}
+
+// signCLA stores the signature when the comment contains the signing phrase
+// and reports the CLA status again when the comment is on a pull request.
+func (b *Bot) signCLA() error {
+	cla := b.config.CLA
+
+	if !strings.Contains(strings.ToLower(b.payload.Comment.Body), strings.ToLower(cla.phrase())) {
+		return nil
+	}
+
+	login := b.payload.Comment.User.Login
+	now := b.now().UTC().Format(time.RFC3339)
+
+	if err := b.getStore().Set(b.signatureKey(login), []byte(now)); err != nil {
+		return errors.Wrap(err, "set cla signature")
+	}
+
+	if b.payload.Issue.PullRequest == nil {
+		return nil
+	}
+
+	pr, _, err := b.client.PullRequests.Get(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, b.payload.Issue.Number)
+	if err != nil {
+		return errors.Wrap(err, "github get pull request")
+	}
+
+	return b.reportCLA(b.payload.Issue.Number, pr.GetHead().GetSHA())
+}
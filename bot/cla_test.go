@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestBotCLA(t *testing.T) {
+	client := newClient(nil)
+	client.PullRequests = &githubPullRequests{authors: []string{"octocat", "frozzare", "monalisa", "octocat"}, head: "def456"}
+	client.Repositories = &githubRepositories{
+		config: `
+pull_request:
+  disabled: true
+cla:
+  document: https://example.com/cla
+  message: "@{users} please sign: @{phrase}"
+  phrase: I sign the CLA
+  signed_file: .github/cla.txt
+`,
+		files:   map[string]string{".github/cla.txt": "# Signed\n@monalisa 2018-01-01\n"},
+		members: []string{"frozzare"},
+	}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	pullRequest := func(action string) error {
+		return b.Handle(newEventRequest("pull_request", `
+			{
+				"action": "`+action+`",
+				"pull_request": {
+					"number": 14,
+					"head": {
+						"sha": "abc123"
+					}
+				},
+				"repository": {
+					"name": "hellobot",
+					"owner": {
+						"login": "frozzare"
+					}
+				},
+				"sender": {
+					"login": "octocat"
+				}
+			}
+		`))
+	}
+
+	// The hello message is disabled for pull requests, but the checks runs.
+	if err := pullRequest("opened"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pullRequest("synchronize"); err != nil {
+		t.Fatal(err)
+	}
+
+	repositories := client.Repositories.(*githubRepositories)
+	issues := client.Issues.(*githubIssues)
+
+	if len(repositories.statuses) != 2 || repositories.statuses[1].GetState() != "failure" || repositories.statuses[1].GetContext() != "license/cla" {
+		t.Fatalf("Unexpected statuses %v", repositories.statuses)
+	}
+
+	// The same unsigned contributors are only asked to sign once.
+	if !reflect.DeepEqual(issues.comments, []string{"@octocat please sign: I sign the CLA"}) {
+		t.Fatalf("Unexpected comments %v", issues.comments)
+	}
+
+	err := b.Handle(newEventRequest("issue_comment", `
+		{
+			"action": "created",
+			"issue": {
+				"number": 14,
+				"pull_request": {
+					"url": "https://api.github.com/repos/frozzare/hellobot/pulls/14"
+				}
+			},
+			"comment": {
+				"body": "I sign the CLA.",
+				"user": {
+					"login": "octocat"
+				}
+			},
+			"repository": {
+				"name": "hellobot",
+				"owner": {
+					"login": "frozzare"
+				}
+			},
+			"sender": {
+				"login": "octocat"
+			}
+		}
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(repositories.refs, []string{"abc123", "abc123", "def456"}) || repositories.statuses[2].GetState() != "success" {
+		t.Fatalf("Unexpected statuses %v for %v", repositories.statuses, repositories.refs)
+	}
+}
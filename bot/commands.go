@@ -0,0 +1,248 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// commandRegexp matches slash commands at the start of a line, like `/label bug`.
+var commandRegexp = regexp.MustCompile(`(?m)^/([a-z][a-z-]*)[ \t]*(.*)$`)
+
+// roles maps the roles that can be configured for commands to the
+// permission level required.
+var roles = map[string]int{
+	"anyone": 0,
+	"read":   1,
+	"write":  2,
+	"admin":  3,
+}
+
+// command represents a slash command in a comment.
+type command struct {
+	name string
+	args []string
+}
+
+// commands contains the commands the bot can run.
+var commands = map[string]func(*Bot, command) error{
+	"label":           (*Bot).labelCommand,
+	"assign":          (*Bot).assignCommand,
+	"close":           (*Bot).closeCommand,
+	"reopen":          (*Bot).reopenCommand,
+	"hello":           (*Bot).helloCommand,
+	"unsubscribe-bot": (*Bot).unsubscribeCommand,
+}
+
+// parseCommands returns the slash commands in the comment, commands in
+// code blocks are ignored.
+func parseCommands(body string) []command {
+	var cmds []command
+
+	for _, match := range commandRegexp.FindAllStringSubmatch(codeRegexp.ReplaceAllString(body, ""), -1) {
+		if _, ok := commands[match[1]]; !ok {
+			continue
+		}
+
+		args := strings.Fields(match[2])
+
+		// Arguments with spaces, like labels, can be separated by commas.
+		if strings.Contains(match[2], ",") {
+			args = nil
+
+			for _, arg := range strings.Split(match[2], ",") {
+				if arg = strings.TrimSpace(arg); len(arg) > 0 {
+					args = append(args, arg)
+				}
+			}
+		}
+
+		cmds = append(cmds, command{name: match[1], args: args})
+	}
+
+	return cmds
+}
+
+// runCommands runs the slash commands in the comment that the commenter is
+// allowed to run. It reacts to the comment when all commands succeeded and
+// replies with the errors otherwise.
+func (b *Bot) runCommands() error {
+	cmds := parseCommands(b.payload.Comment.Body)
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	var failed []string
+
+	for _, cmd := range cmds {
+		if err := b.runCommand(cmd); err != nil {
+			failed = append(failed, fmt.Sprintf("`/%s`: %s", cmd.name, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		message := fmt.Sprintf("@%s some commands failed:\n\n* %s", b.payload.Comment.User.Login, strings.Join(failed, "\n* "))
+		return b.comment(b.payload.Issue.Number, message)
+	}
+
+	_, _, err := b.client.Reactions.CreateIssueCommentReaction(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, b.payload.Comment.ID, "+1")
+
+	return errors.Wrap(err, "github create issue comment reaction")
+}
+
+// runCommand runs the command if it is enabled and the commenter has the role.
+func (b *Bot) runCommand(cmd command) error {
+	role, ok := b.config.Commands[cmd.name]
+	if !ok {
+		return errors.New("command is not enabled")
+	}
+
+	allowed, err := b.hasRole(role)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return errors.Errorf("requires the %s role", role)
+	}
+
+	if err := commands[cmd.name](b, cmd); err != nil {
+		return err
+	}
+
+	return b.record(b.payload.Issue.Number, "command /"+cmd.name)
+}
+
+// hasRole returns true if the commenter has the role. The author role is
+// the issue or pull request author or users with write permission.
+func (b *Bot) hasRole(role string) (bool, error) {
+	login := b.payload.Comment.User.Login
+
+	if len(role) == 0 {
+		role = "write"
+	}
+
+	if role == "author" {
+		if strings.ToLower(login) == strings.ToLower(b.payload.Issue.User.Login) {
+			return true, nil
+		}
+
+		role = "write"
+	}
+
+	required, ok := roles[role]
+	if !ok {
+		return false, errors.Errorf("unknown role %s", role)
+	}
+
+	if required == 0 {
+		return true, nil
+	}
+
+	level, _, err := b.client.Repositories.GetPermissionLevel(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, login)
+	if err != nil {
+		return false, errors.Wrap(err, "github get permission level")
+	}
+
+	return roles[level.GetPermission()] >= required, nil
+}
+
+// labelCommand adds the labels to the issue or pull request.
+func (b *Bot) labelCommand(cmd command) error {
+	if len(cmd.args) == 0 {
+		return errors.New("no labels given")
+	}
+
+	_, _, err := b.client.Issues.AddLabelsToIssue(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, b.payload.Issue.Number, cmd.args)
+
+	return errors.Wrap(err, "github add labels to issue")
+}
+
+// assignCommand assigns the users, or the commenter when no users are
+// given, to the issue or pull request.
+func (b *Bot) assignCommand(cmd command) error {
+	var assignees []string
+
+	for _, arg := range cmd.args {
+		if arg == "me" {
+			arg = b.payload.Comment.User.Login
+		}
+
+		assignees = append(assignees, strings.TrimPrefix(arg, "@"))
+	}
+
+	if len(assignees) == 0 {
+		assignees = []string{b.payload.Comment.User.Login}
+	}
+
+	_, _, err := b.client.Issues.AddAssignees(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, b.payload.Issue.Number, assignees)
+
+	return errors.Wrap(err, "github add assignees")
+}
+
+// closeCommand closes the issue or pull request.
+func (b *Bot) closeCommand(cmd command) error {
+	return b.setState("closed")
+}
+
+// reopenCommand reopens the issue or pull request.
+func (b *Bot) reopenCommand(cmd command) error {
+	return b.setState("open")
+}
+
+// setState sets the state of the issue or pull request.
+func (b *Bot) setState(state string) error {
+	_, _, err := b.client.Issues.Edit(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, b.payload.Issue.Number, &github.IssueRequest{
+		State: github.String(state),
+	})
+
+	return errors.Wrap(err, "github edit issue")
+}
+
+// helloCommand sends the greeting message again.
+func (b *Bot) helloCommand(cmd command) error {
+	item, err := b.item()
+	if err != nil {
+		return err
+	}
+
+	if item.Disabled {
+		return errors.New("greeting is disabled")
+	}
+
+	message, _, err := b.greeting(item)
+	if err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(message)) == 0 {
+		return errors.New("no greeting message")
+	}
+
+	return b.comment(b.payload.Issue.Number, renderMessage(message, b.payload.Issue.User.Login))
+}
+
+// unsubscribeCommand stops the bot from commenting, labeling, closing or
+// locking the issue or pull request.
+func (b *Bot) unsubscribeCommand(cmd command) error {
+	return errors.Wrap(b.getStore().Set(b.unsubscribedKey(b.payload.Issue.Number), []byte("1")), "set unsubscribed")
+}
+
+// unsubscribedKey returns the store key for the unsubscribed issue or pull request.
+func (b *Bot) unsubscribedKey(number int) string {
+	return "unsubscribed/" + b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name + "/" + strconv.Itoa(number)
+}
+
+// unsubscribed returns true if the bot has been unsubscribed from the issue or pull request.
+func (b *Bot) unsubscribed(number int) (bool, error) {
+	buf, err := b.getStore().Get(b.unsubscribedKey(number))
+	if err != nil {
+		return false, errors.Wrap(err, "get unsubscribed")
+	}
+
+	return buf != nil, nil
+}
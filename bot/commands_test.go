@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestParseCommands(t *testing.T) {
+	body := "Thanks!\n/label bug, help wanted\n/assign @octocat me\n```\n/close\n```\n/unknown\nNot a /reopen command"
+
+	expected := []command{
+		{name: "label", args: []string{"bug", "help wanted"}},
+		{name: "assign", args: []string{"@octocat", "me"}},
+	}
+
+	if cmds := parseCommands(body); !reflect.DeepEqual(cmds, expected) {
+		t.Fatalf("Expected %v, got %v", expected, cmds)
+	}
+}
+
+func newCommentRequest(login, body string) string {
+	return `
+		{
+			"action": "created",
+			"issue": {
+				"number": 12,
+				"user": {
+					"login": "octocat"
+				}
+			},
+			"comment": {
+				"id": 42,
+				"body": "` + body + `",
+				"user": {
+					"login": "` + login + `"
+				}
+			},
+			"repository": {
+				"name": "hellobot",
+				"owner": {
+					"login": "frozzare"
+				}
+			},
+			"sender": {
+				"login": "` + login + `"
+			}
+		}
+	`
+}
+
+func TestBotCommands(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{
+		config: `
+issue:
+  message: Hello @{author}!
+commands:
+  label: write
+  close: author
+  hello: anyone
+  unsubscribe-bot: author
+`,
+		members: []string{"frozzare"},
+	}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	comment := func(login, body string) {
+		if err := b.Handle(newEventRequest("issue_comment", newCommentRequest(login, body))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	issues := client.Issues.(*githubIssues)
+	reactions := client.Reactions.(*githubReactions)
+
+	comment("frozzare", `/label bug\n/hello`)
+
+	if !reflect.DeepEqual(issues.labels, []string{"bug"}) || !reflect.DeepEqual(issues.comments, []string{"Hello octocat!"}) {
+		t.Fatalf("Unexpected labels %v and comments %v", issues.labels, issues.comments)
+	}
+
+	if !reflect.DeepEqual(reactions.comments, []string{"+1"}) {
+		t.Fatalf("Expected +1 reaction, got %v", reactions.comments)
+	}
+
+	comment("monalisa", `/label wontfix\n/close\n/assign me`)
+
+	expected := "@monalisa some commands failed:\n\n* `/label`: requires the write role\n* `/close`: requires the author role\n* `/assign`: command is not enabled"
+	if len(issues.comments) != 2 || issues.comments[1] != expected {
+		t.Fatalf("Expected error reply, got %v", issues.comments)
+	}
+
+	comment("octocat", `/close`)
+
+	if len(issues.edits) != 1 || issues.edits[0].GetState() != "closed" {
+		t.Fatalf("Expected issue to be closed, got %v", issues.edits)
+	}
+
+	comment("octocat", `/unsubscribe-bot`)
+
+	issues.issues = []*github.Issue{newStaleIssue(12, time.Now())}
+
+	rb := b.repositoryBot(context.Background(), client, 1, "frozzare", "hellobot")
+	rb.config = b.config
+	rb.clock = func() time.Time { return time.Now().AddDate(1, 0, 0) }
+
+	actions, err := rb.sweepRepository(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actions) != 0 {
+		t.Fatalf("Expected unsubscribed issue to be skipped, got %v", actions)
+	}
+}
+
+func TestBotHelloCommandSchedule(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+issue:
+  message: Hello issue @{author}
+pull_request:
+  message: Hello pull request @{author}
+commands:
+  hello: anyone
+schedules:
+  - name: vacation
+    dates:
+      - from: 2018-12-20
+        to: 2019-01-06
+    issue:
+      message: Away from issues.
+    pull_request:
+      message: Away from pull requests.
+`}
+
+	now := time.Date(2018, 12, 24, 12, 0, 0, 0, time.UTC)
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore(), clock: func() time.Time { return now }}
+
+	// Comments on pull requests are issue comments with a pull request link.
+	body := strings.Replace(newCommentRequest("frozzare", "/hello"), `"number": 12,`, `"number": 12,
+				"pull_request": {"url": "https://api.github.com/repos/frozzare/hellobot/pulls/12"},`, 1)
+
+	if err := b.Handle(newEventRequest("issue_comment", body)); err != nil {
+		t.Fatal(err)
+	}
+
+	if comments := client.Issues.(*githubIssues).comments; !reflect.DeepEqual(comments, []string{"Away from pull requests."}) {
+		t.Fatalf("Expected pull request override, got %v", comments)
+	}
+}
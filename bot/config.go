@@ -1,17 +1,375 @@
 package bot
 
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
 // Config represents `.hello.yml` file.
 type Config struct {
 	Ignore struct {
 		Users  []string `yaml:"users"`
 		Labels []string `yaml:"labels"`
 	} `yaml:"ignore"`
-	Issue       Item `yaml:"issue"`
-	PullRequest Item `yaml:"pull_request"`
+	Issue       Item              `yaml:"issue"`
+	PullRequest Item              `yaml:"pull_request"`
+	Discussion  Item              `yaml:"discussion"`
+	Milestones  Milestones        `yaml:"milestones"`
+	Release     Release           `yaml:"release"`
+	Fixes       Fixes             `yaml:"fixes"`
+	CLA         CLA               `yaml:"cla"`
+	DCO         DCO               `yaml:"dco"`
+	Semantic    Semantic          `yaml:"semantic"`
+	Commands    map[string]string `yaml:"commands"`
+	Stale       Stale             `yaml:"stale"`
+	Lock        Lock              `yaml:"lock"`
+	Timezone    string            `yaml:"timezone"`
+	Schedules   []Schedule        `yaml:"schedules"`
 }
 
 type Item struct {
-	Disabled bool     `yaml:"disabled"`
+	Disabled       bool               `yaml:"disabled"`
+	Labels         []string           `yaml:"labels"`
+	Message        string             `yaml:"message"`
+	MessageFile    string             `yaml:"message_file"`
+	Messages       []Variant          `yaml:"messages"`
+	Selection      string             `yaml:"selection"`
+	Languages      map[string]Variant `yaml:"languages"`
+	LanguageLabels bool               `yaml:"language_labels"`
+	Reaction       string             `yaml:"reaction"`
+	Paths          []PathRule         `yaml:"paths"`
+	Reviewers      Assign             `yaml:"reviewers"`
+	Assignees      Assign             `yaml:"assignees"`
+}
+
+// Variant represents one of several messages for an item. Variants can be
+// written as plain strings when no message file or weight is needed.
+type Variant struct {
+	Message     string `yaml:"message"`
+	MessageFile string `yaml:"message_file"`
+	Weight      int    `yaml:"weight"`
+}
+
+// UnmarshalYAML unmarshals a variant from a string or a map.
+func (v *Variant) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var message string
+	if err := unmarshal(&message); err == nil {
+		*v = Variant{Message: message}
+		return nil
+	}
+
+	type variant Variant
+	return unmarshal((*variant)(v))
+}
+
+// weight returns the variant weight, defaults to 1.
+func (v Variant) weight() int {
+	if v.Weight <= 0 {
+		return 1
+	}
+
+	return v.Weight
+}
+
+// reactions contains the reactions GitHub supports.
+var reactions = []string{"+1", "-1", "laugh", "confused", "heart", "hooray", "rocket", "eyes"}
+
+// validReaction returns true if the reaction is supported by GitHub.
+func validReaction(reaction string) bool {
+	for _, r := range reactions {
+		if r == reaction {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PathRule represents labels and a message paragraph that is added
+// when a pull request changes files matching any of the patterns.
+type PathRule struct {
+	Patterns []string `yaml:"patterns"`
 	Labels   []string `yaml:"labels"`
 	Message  string   `yaml:"message"`
 }
+
+// Assign represents how reviewers or assignees are selected.
+//
+// Candidates are code owners of the changed files (pull requests only),
+// users and team members. Teams, like `org/team`, are requested as reviewers
+// but replaced by their members for assignees, since teams can't be assigned.
+// The strategy orders the candidates (all, round_robin or load_balance) and
+// max limits how many of them that are selected.
+type Assign struct {
+	Codeowners bool     `yaml:"codeowners"`
+	Users      []string `yaml:"users"`
+	Team       []string `yaml:"team"`
+	Strategy   string   `yaml:"strategy"`
+	Max        int      `yaml:"max"`
+}
+
+// empty returns true when no candidates are configured.
+func (a Assign) empty() bool {
+	return !a.Codeowners && len(a.Users) == 0 && len(a.Team) == 0
+}
+
+// Stale represents the sweep that marks and closes issues and pull requests
+// without activity. The sweep is disabled when days until stale is zero.
+type Stale struct {
+	DaysUntilStale int      `yaml:"days_until_stale"`
+	DaysUntilClose int      `yaml:"days_until_close"`
+	Label          string   `yaml:"label"`
+	ExemptLabels   []string `yaml:"exempt_labels"`
+	Message        string   `yaml:"message"`
+	CloseMessage   string   `yaml:"close_message"`
+	DryRun         bool     `yaml:"dry_run"`
+}
+
+// label returns the stale label, defaults to `stale`.
+func (s Stale) label() string {
+	if len(s.Label) == 0 {
+		return "stale"
+	}
+
+	return s.Label
+}
+
+// Lock represents the sweep that locks issues and pull requests that have been
+// closed for a long time. The sweep is disabled when days is zero.
+type Lock struct {
+	Days         int      `yaml:"days"`
+	Message      string   `yaml:"message"`
+	ExemptLabels []string `yaml:"exempt_labels"`
+	Limit        int      `yaml:"limit"`
+	DryRun       bool     `yaml:"dry_run"`
+}
+
+// limit returns how many issues and pull requests that are locked per sweep
+// of all repositories, defaults to 50.
+func (l Lock) limit() int {
+	if l.Limit <= 0 {
+		return 50
+	}
+
+	return l.Limit
+}
+
+// Schedule represents messages and labels used during a period of time, like
+// vacations or outside office hours. The first matching schedule is used.
+//
+// Dates are inclusive ranges, weekdays are weekday names and hours is a window
+// like `09:00-17:00`. Outside inverts the weekdays and hours window.
+type Schedule struct {
+	Name        string      `yaml:"name"`
+	Timezone    string      `yaml:"timezone"`
+	Dates       []DateRange `yaml:"dates"`
+	Weekdays    []string    `yaml:"weekdays"`
+	Hours       string      `yaml:"hours"`
+	Outside     bool        `yaml:"outside"`
+	Issue       Override    `yaml:"issue"`
+	PullRequest Override    `yaml:"pull_request"`
+}
+
+// DateRange represents an inclusive range of dates like `2018-12-20`.
+type DateRange struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// Override represents the message and labels that overrides an item.
+type Override struct {
+	Message     string   `yaml:"message"`
+	MessageFile string   `yaml:"message_file"`
+	Labels      []string `yaml:"labels"`
+}
+
+// Milestones represents the issue created when the repository reaches
+// one of the star or fork counts for the first time.
+type Milestones struct {
+	Stars   []int    `yaml:"stars"`
+	Forks   []int    `yaml:"forks"`
+	Title   string   `yaml:"title"`
+	Message string   `yaml:"message"`
+	Labels  []string `yaml:"labels"`
+}
+
+// title returns the issue title, defaults to `🎉 @{count} @{kind}`.
+func (m Milestones) title() string {
+	if len(m.Title) == 0 {
+		return "🎉 @{count} @{kind}"
+	}
+
+	return m.Title
+}
+
+// Release represents the comment written on issues referenced in the
+// notes of a published release. Releases are disabled without message.
+type Release struct {
+	Message     string   `yaml:"message"`
+	Labels      []string `yaml:"labels"`
+	Prereleases bool     `yaml:"prereleases"`
+}
+
+// Fixes represents the comments written on issues when a pull request that
+// fixes them is merged and when the pull request is included in a release.
+type Fixes struct {
+	MergedMessage   string   `yaml:"merged_message"`
+	ReleasedMessage string   `yaml:"released_message"`
+	Labels          []string `yaml:"labels"`
+}
+
+// tracked returns true when fixed issues should be tracked until released.
+func (f Fixes) tracked() bool {
+	return len(f.ReleasedMessage) > 0 || len(f.Labels) > 0
+}
+
+// CLA represents the Contributor License Agreement check for pull requests
+// from outside contributors. Contributors sign by commenting the phrase.
+type CLA struct {
+	Document   string   `yaml:"document"`
+	Message    string   `yaml:"message"`
+	Phrase     string   `yaml:"phrase"`
+	SignedFile string   `yaml:"signed_file"`
+	Allowlist  []string `yaml:"allowlist"`
+	Context    string   `yaml:"context"`
+}
+
+// enabled returns true when the CLA check is enabled.
+func (c CLA) enabled() bool {
+	return len(c.Document) > 0
+}
+
+// message returns the comment written when contributors must sign the CLA.
+func (c CLA) message() string {
+	if len(c.Message) == 0 {
+		return "Thank you for your contribution! Before we can merge it @{users} must sign our [Contributor License Agreement](@{document}) by commenting:\n\n> @{phrase}"
+	}
+
+	return c.Message
+}
+
+// phrase returns the phrase contributors comment to sign the CLA.
+func (c CLA) phrase() string {
+	if len(c.Phrase) == 0 {
+		return "I have read the CLA Document and I hereby sign the CLA"
+	}
+
+	return c.Phrase
+}
+
+// context returns the commit status context.
+func (c CLA) context() string {
+	if len(c.Context) == 0 {
+		return "license/cla"
+	}
+
+	return c.Context
+}
+
+// DCO represents the Developer Certificate of Origin check that requires
+// pull request commits to be signed off by the commit author.
+type DCO struct {
+	Enabled       bool     `yaml:"enabled"`
+	Name          string   `yaml:"name"`
+	ExemptBots    bool     `yaml:"exempt_bots"`
+	ExemptMembers bool     `yaml:"exempt_members"`
+	ExemptUsers   []string `yaml:"exempt_users"`
+}
+
+// name returns the check run name.
+func (d DCO) name() string {
+	if len(d.Name) == 0 {
+		return "DCO"
+	}
+
+	return d.Name
+}
+
+// Semantic represents the check that validates pull request titles, and
+// optionally commit messages, against Conventional Commits.
+type Semantic struct {
+	Enabled bool              `yaml:"enabled"`
+	Name    string            `yaml:"name"`
+	Commits bool              `yaml:"commits"`
+	Types   []string          `yaml:"types"`
+	Scopes  []string          `yaml:"scopes"`
+	Labels  map[string]string `yaml:"labels"`
+	Message string            `yaml:"message"`
+}
+
+// name returns the check run name.
+func (s Semantic) name() string {
+	if len(s.Name) == 0 {
+		return "Semantic Pull Request"
+	}
+
+	return s.Name
+}
+
+// types returns the allowed types, defaults to the Conventional Commits types.
+func (s Semantic) types() []string {
+	if len(s.Types) == 0 {
+		return []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+	}
+
+	return s.Types
+}
+
+// message returns the comment written when the check fails.
+func (s Semantic) message() string {
+	if len(s.Message) == 0 {
+		return "Thank you for your contribution @{author}! Please use a [Conventional Commits](https://www.conventionalcommits.org/) title like `type(scope): description`, where type is one of @{types}.\n\n@{errors}"
+	}
+
+	return s.Message
+}
+
+// problems returns the problems in the config, like unknown reactions and strategies.
+func (c *Config) problems() []string {
+	var problems []string
+
+	items := []struct {
+		name string
+		item Item
+	}{{"issue", c.Issue}, {"pull_request", c.PullRequest}, {"discussion", c.Discussion}}
+
+	for _, i := range items {
+		if len(i.item.Reaction) > 0 && !validReaction(i.item.Reaction) {
+			problems = append(problems, fmt.Sprintf("%s: unknown reaction %s", i.name, i.item.Reaction))
+		}
+
+		for _, assign := range []Assign{i.item.Reviewers, i.item.Assignees} {
+			switch assign.Strategy {
+			case "", "all", "round_robin", "load_balance":
+			default:
+				problems = append(problems, fmt.Sprintf("%s: unknown strategy %s", i.name, assign.Strategy))
+			}
+		}
+	}
+
+	for name, role := range c.Commands {
+		if _, ok := commands[name]; !ok {
+			problems = append(problems, fmt.Sprintf("commands: unknown command %s", name))
+		}
+
+		if _, ok := roles[role]; !ok && role != "author" && len(role) > 0 {
+			problems = append(problems, fmt.Sprintf("commands: unknown role %s", role))
+		}
+	}
+
+	timezones := []string{c.Timezone}
+	for _, schedule := range c.Schedules {
+		timezones = append(timezones, schedule.Timezone)
+	}
+
+	for _, timezone := range timezones {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			problems = append(problems, fmt.Sprintf("unknown timezone %s", timezone))
+		}
+	}
+
+	sort.Strings(problems)
+
+	return problems
+}
@@ -0,0 +1,177 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// signoffRegexp matches sign-off trailers like `Signed-off-by: Name <email>`.
+var signoffRegexp = regexp.MustCompile(`(?im)^\s*Signed-off-by:\s*(.*?)\s*<([^>]+)>\s*$`)
+
+// checkDCO creates a check run that tells if all pull request commits are
+// signed off by the commit author, with details for the failing commits.
+func (b *Bot) checkDCO(number int, sha string) error {
+	if b.client.Checks == nil {
+		return errors.New("No GitHub checks client")
+	}
+
+	commits, err := b.listCommits(number)
+	if err != nil {
+		return err
+	}
+
+	exempt := map[string]bool{}
+	merges := false
+	var failures []string
+
+	for _, commit := range commits {
+		// Merge commits are created by GitHub or git and don't need a sign-off.
+		if len(commit.Parents) > 1 {
+			merges = true
+			continue
+		}
+
+		login := commit.GetAuthor().GetLogin()
+
+		if len(login) > 0 {
+			if _, ok := exempt[login]; !ok {
+				exempt[login], err = b.exemptDCO(commit.GetAuthor())
+				if err != nil {
+					return err
+				}
+			}
+
+			if exempt[login] {
+				continue
+			}
+		}
+
+		if reason := signoffFailure(commit.GetCommit()); len(reason) > 0 {
+			failures = append(failures, fmt.Sprintf("* %s %s: %s", shortSHA(commit.GetSHA()), firstLine(commit.GetCommit().GetMessage()), reason))
+		}
+	}
+
+	now := b.now()
+	run := &CheckRun{
+		Name:        b.config.DCO.name(),
+		HeadSHA:     sha,
+		Status:      "completed",
+		Conclusion:  "success",
+		CompletedAt: &now,
+		Output: CheckRunOutput{
+			Title:   "All commits are signed off",
+			Summary: "All commits are signed off by the commit author.",
+		},
+	}
+
+	if len(failures) > 0 {
+		run.Conclusion = "failure"
+		run.Output.Title = fmt.Sprintf("%d of %d commits are not signed off", len(failures), len(commits))
+		run.Output.Summary = "All commits must have a `Signed-off-by` line that matches the commit author, certifying the [Developer Certificate of Origin](https://developercertificate.org/)."
+		// Merge commits are not counted from HEAD, so branches with merges
+		// are rebased from where they leave the base branch instead.
+		upstream := fmt.Sprintf("HEAD~%d", len(commits))
+		if merges {
+			upstream = "$(git merge-base HEAD origin/" + b.baseBranch() + ")"
+		}
+
+		run.Output.Text = strings.Join(failures, "\n") + fmt.Sprintf(`
+
+To sign off the last commit, amend it and push again:
+
+    git commit --amend --signoff --no-edit
+    git push --force-with-lease
+
+To sign off all commits in the pull request, rebase them:
+
+    git rebase %s --signoff
+    git push --force-with-lease`, upstream)
+	}
+
+	_, _, err = b.client.Checks.CreateCheckRun(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, run)
+
+	return errors.Wrap(err, "github create check run")
+}
+
+// exemptDCO returns true if the commit author don't need to sign off commits.
+func (b *Bot) exemptDCO(author *github.User) (bool, error) {
+	dco := b.config.DCO
+	login := author.GetLogin()
+
+	if dco.ExemptBots && (author.GetType() == "Bot" || strings.HasSuffix(login, "[bot]")) {
+		return true, nil
+	}
+
+	for _, user := range dco.ExemptUsers {
+		if strings.ToLower(user) == strings.ToLower(login) {
+			return true, nil
+		}
+	}
+
+	// Only organizations have members, repositories owned by users have none.
+	if !dco.ExemptMembers || b.client.Organizations == nil || b.payload.Repository.Owner.Type != "Organization" {
+		return false, nil
+	}
+
+	member, _, err := b.client.Organizations.IsMember(b.ctx, b.payload.Repository.Owner.Login, login)
+	if err != nil {
+		return false, errors.Wrap(err, "github is member")
+	}
+
+	return member, nil
+}
+
+// baseBranch returns the branch the pull request is merged into.
+func (b *Bot) baseBranch() string {
+	if len(b.payload.PullRequest.Base.Ref) > 0 {
+		return b.payload.PullRequest.Base.Ref
+	}
+
+	if len(b.payload.Repository.DefaultBranch) > 0 {
+		return b.payload.Repository.DefaultBranch
+	}
+
+	return "master"
+}
+
+// signoffFailure returns the reason the commit is not signed off by the
+// author or an empty string when it is.
+func signoffFailure(commit *github.Commit) string {
+	name := commit.GetAuthor().GetName()
+	email := commit.GetAuthor().GetEmail()
+
+	matches := signoffRegexp.FindAllStringSubmatch(commit.GetMessage(), -1)
+	if len(matches) == 0 {
+		return "no `Signed-off-by` line"
+	}
+
+	for _, match := range matches {
+		if strings.ToLower(match[1]) == strings.ToLower(name) && strings.ToLower(match[2]) == strings.ToLower(email) {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("expected `Signed-off-by: %s <%s>`", name, email)
+}
+
+// shortSHA returns the abbreviated commit sha.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+
+	return sha
+}
+
+// firstLine returns the first line of the text.
+func firstLine(text string) string {
+	if i := strings.Index(text, "\n"); i != -1 {
+		return text[:i]
+	}
+
+	return text
+}
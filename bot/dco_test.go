@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func newCommit(sha, login, name, email, message string) *github.RepositoryCommit {
+	return &github.RepositoryCommit{
+		SHA:    github.String(sha),
+		Author: &github.User{Login: github.String(login)},
+		Commit: &github.Commit{
+			Author:  &github.CommitAuthor{Name: github.String(name), Email: github.String(email)},
+			Message: github.String(message),
+		},
+	}
+}
+
+func TestSignoffFailure(t *testing.T) {
+	tests := map[string]string{
+		"Add reactions\n\nSigned-off-by: Octo Cat <octocat@github.com>": "",
+		"Add reactions\n\nsigned-off-by: octo cat <OCTOCAT@github.com>": "",
+		"Add reactions": "no `Signed-off-by` line",
+		"Add reactions\n\nSigned-off-by: Mona Lisa <monalisa@github.com>": "expected `Signed-off-by: Octo Cat <octocat@github.com>`",
+	}
+
+	for message, expected := range tests {
+		commit := newCommit("abc", "octocat", "Octo Cat", "octocat@github.com", message).Commit
+		if reason := signoffFailure(commit); reason != expected {
+			t.Errorf("signoffFailure(%q) = %q, want %q", message, reason, expected)
+		}
+	}
+}
+
+func TestBotDCO(t *testing.T) {
+	merge := newCommit("0000000merge", "octocat", "Octo Cat", "octocat@github.com", "Merge branch 'master'")
+	merge.Parents = []github.Commit{{}, {}}
+
+	client := newClient(nil)
+	client.Organizations = &githubOrganizations{members: []string{"frozzare"}}
+	client.PullRequests = &githubPullRequests{list: []*github.RepositoryCommit{
+		newCommit("1111111aaa", "octocat", "Octo Cat", "octocat@github.com", "Add reactions\n\nSigned-off-by: Octo Cat <octocat@github.com>"),
+		newCommit("2222222bbb", "octocat", "Octo Cat", "octocat@github.com", "Fix typo"),
+		newCommit("3333333ccc", "frozzare", "Fredrik", "fredrik@example.com", "Update readme"),
+		newCommit("4444444ddd", "dependabot[bot]", "dependabot", "bot@example.com", "Bump yaml"),
+		merge,
+	}}
+	client.Repositories = &githubRepositories{config: `
+pull_request:
+  disabled: true
+dco:
+  enabled: true
+  exempt_bots: true
+  exempt_members: true
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	pullRequest := func(ownerType string) *CheckRun {
+		err := b.Handle(newEventRequest("pull_request", `
+			{
+				"action": "synchronize",
+				"pull_request": {
+					"number": 14,
+					"head": {
+						"sha": "abc123"
+					},
+					"base": {
+						"ref": "main"
+					}
+				},
+				"repository": {
+					"name": "hellobot",
+					"owner": {
+						"login": "frozzare",
+						"type": "`+ownerType+`"
+					}
+				},
+				"sender": {
+					"login": "octocat"
+				}
+			}
+		`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		runs := client.Checks.(*githubChecks).runs
+		return runs[len(runs)-1]
+	}
+
+	run := pullRequest("Organization")
+	if run.Name != "DCO" || run.HeadSHA != "abc123" || run.Conclusion != "failure" || run.Output.Title != "1 of 5 commits are not signed off" {
+		t.Fatalf("Unexpected check run %+v", run)
+	}
+
+	// Merge commits are not counted from HEAD, so the branch is rebased from the base branch.
+	if !strings.HasPrefix(run.Output.Text, "* 2222222 Fix typo: no `Signed-off-by` line\n") || !strings.Contains(run.Output.Text, "git rebase $(git merge-base HEAD origin/main) --signoff") {
+		t.Fatalf("Unexpected check run text %q", run.Output.Text)
+	}
+
+	// Repositories owned by users has no members.
+	if run := pullRequest("User"); run.Output.Title != "2 of 5 commits are not signed off" {
+		t.Fatalf("Expected members not to be exempt, got %q", run.Output.Title)
+	}
+
+	// Without merge commits all commits are rebased from HEAD.
+	client.PullRequests.(*githubPullRequests).list = client.PullRequests.(*githubPullRequests).list[:4]
+
+	if run := pullRequest("Organization"); !strings.Contains(run.Output.Text, "git rebase HEAD~4 --signoff") {
+		t.Fatalf("Unexpected check run text %q", run.Output.Text)
+	}
+}
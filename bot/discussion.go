@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// addDiscussionCommentMutation adds a comment to a discussion.
+const addDiscussionCommentMutation = `
+mutation($discussionId: ID!, $body: String!) {
+  addDiscussionComment(input: {discussionId: $discussionId, body: $body}) {
+    comment {
+      id
+    }
+  }
+}`
+
+// greetDiscussion writes a hello comment to a new discussion.
+func (b *Bot) greetDiscussion(payload *DiscussionPayload) error {
+	// Only discussions with "created" action is allowed.
+	if payload.Action != "created" {
+		return skip("Only created action is handled")
+	}
+
+	if err := b.prepare(payload.payload()); err != nil {
+		return err
+	}
+
+	// Validate payload with config values.
+	if err := b.validatePayload(); err != nil {
+		return errors.Wrap(err, "validate payload")
+	}
+
+	item := b.config.Discussion
+	if item.Disabled {
+		return skip("Item disabled")
+	}
+
+	message, err := b.itemMessage(item)
+	if err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(message)) == 0 {
+		return skip("No discussion message")
+	}
+
+	if b.client.GraphQL == nil {
+		return errors.New("No GitHub GraphQL client")
+	}
+
+	err = b.client.GraphQL.Query(b.ctx, addDiscussionCommentMutation, map[string]interface{}{
+		"discussionId": payload.Discussion.NodeID,
+		"body":         renderMessage(message, b.payload.Sender.Login),
+	}, nil)
+
+	return errors.Wrap(err, "github add discussion comment")
+}
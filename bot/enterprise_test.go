@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestBotEnterprise(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/v3/installations/1234/access_tokens":
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"token":      "installation-token",
+				"expires_at": time.Now().Add(time.Hour),
+			})
+		case "/api/v3/repos/test/Fredrik/issues/1/comments", "/api/graphql":
+			if r.Header.Get("Authorization") != "token installation-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.Write([]byte(`{}`))
+		case "/api/v3/app/installations":
+			w.Write([]byte(`[{"id": 1234}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	key, err := NewPrivateKey(newKeyPEM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBot(1, key)
+	if err := b.SetBaseURLs(server.URL+"/api/v3", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.uploadURL.String() != server.URL+"/api/uploads/" {
+		t.Fatalf("Expected enterprise upload url, got %s", b.uploadURL)
+	}
+
+	client, err := b.installationClient(1234)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.Issues.CreateComment(b.ctx, "test", "Fredrik", 1, &github.IssueComment{Body: github.String("Hello")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.GraphQL.Query(b.ctx, "query { viewer { login } }", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	appClient, err := b.createAppClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	installations, _, err := appClient.Apps.ListInstallations(b.ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(installations) != 1 || installations[0].GetID() != 1234 {
+		t.Fatalf("Unexpected installations %v", installations)
+	}
+
+	expected := []string{
+		"POST /api/v3/installations/1234/access_tokens",
+		"POST /api/v3/repos/test/Fredrik/issues/1/comments",
+		"POST /api/graphql",
+		"GET /api/v3/app/installations",
+	}
+
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Unexpected requests %q", requests)
+	}
+}
+
+func TestBotBaseURLs(t *testing.T) {
+	b := &Bot{}
+
+	if err := b.SetBaseURLs("https://api.github.com/", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.uploadURL.String() != "https://uploads.github.com/" {
+		t.Fatalf("Expected github.com upload url, got %s", b.uploadURL)
+	}
+
+	if b.apiURL() != "https://api.github.com" {
+		t.Fatalf("Unexpected api url %s", b.apiURL())
+	}
+
+	if err := b.SetBaseURLs("https://github.example.com/api/v3/", "https://uploads.example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.uploadURL.String() != "https://uploads.example.com/" {
+		t.Fatalf("Expected configured upload url, got %s", b.uploadURL)
+	}
+}
@@ -0,0 +1,273 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newEventRequest(event, body string) *http.Request {
+	r := newRequest(body)
+	r.Header = http.Header{}
+	r.Header.Set("X-GitHub-Event", event)
+
+	return r
+}
+
+func TestBotDiscussion(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+discussion:
+  message: Thanks for starting a discussion @{author}!
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	err := b.Handle(newEventRequest("discussion", `
+		{
+			"action": "created",
+			"discussion": {
+				"node_id": "D_1",
+				"number": 1,
+				"title": "Ideas"
+			},
+			"repository": {
+				"name": "hellobot",
+				"owner": {
+					"login": "frozzare"
+				}
+			},
+			"sender": {
+				"login": "octocat"
+			}
+		}
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []map[string]interface{}{{"discussionId": "D_1", "body": "Thanks for starting a discussion octocat!"}}
+	if variables := client.GraphQL.(*githubGraphQL).variables; !reflect.DeepEqual(variables, expected) {
+		t.Fatalf("Expected %v, got %v", expected, variables)
+	}
+}
+
+func TestBotStarMilestone(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+milestones:
+  stars:
+    - 100
+    - 1000
+  message: Thank you all!
+  labels:
+    - celebration
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	star := func(count string) error {
+		return b.Handle(newEventRequest("star", `
+			{
+				"action": "created",
+				"repository": {
+					"name": "hellobot",
+					"stargazers_count": `+count+`,
+					"owner": {
+						"login": "frozzare"
+					}
+				},
+				"sender": {
+					"login": "octocat"
+				}
+			}
+		`))
+	}
+
+	if err := star("99"); err == nil {
+		t.Fatal("Expected no milestone for 99 stars")
+	}
+
+	// Stars can be added at the same time, so the milestone can be passed.
+	if err := star("1002"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Milestones are only celebrated the first time.
+	if err := star("1003"); err == nil {
+		t.Fatal("Expected milestone to only be celebrated once")
+	}
+
+	created := client.Issues.(*githubIssues).created
+	if len(created) != 1 {
+		t.Fatalf("Expected one issue, got %d", len(created))
+	}
+
+	if title := created[0].GetTitle(); title != "🎉 1,000 stars" {
+		t.Fatalf("Unexpected title %q", title)
+	}
+
+	if labels := created[0].GetLabels(); !reflect.DeepEqual(labels, []string{"celebration"}) {
+		t.Fatalf("Unexpected labels %v", labels)
+	}
+}
+
+func TestBotRelease(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+release:
+  message: This has been released in [@{tag}](@{url}).
+  labels:
+    - released
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	err := b.Handle(newEventRequest("release", `
+		{
+			"action": "published",
+			"release": {
+				"tag_name": "v1.2.0",
+				"html_url": "https://github.com/frozzare/hellobot/releases/v1.2.0",
+				"body": "* Fixes #12\n* Add reactions (#14, #12)\n* See frozzare/other#3 and https://example.com/#5"
+			},
+			"repository": {
+				"name": "hellobot",
+				"owner": {
+					"login": "frozzare"
+				}
+			},
+			"sender": {
+				"login": "frozzare"
+			}
+		}
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues := client.Issues.(*githubIssues)
+
+	if !reflect.DeepEqual(issues.commented, []int{12, 14}) {
+		t.Fatalf("Expected comments on 12 and 14, got %v", issues.commented)
+	}
+
+	if issues.comments[0] != "This has been released in [v1.2.0](https://github.com/frozzare/hellobot/releases/v1.2.0)." {
+		t.Fatalf("Unexpected comment %q", issues.comments[0])
+	}
+
+	if !reflect.DeepEqual(issues.labels, []string{"released", "released"}) {
+		t.Fatalf("Unexpected labels %v", issues.labels)
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for count, expected := range map[int]string{1: "1", 100: "100", 1000: "1,000", 1234567: "1,234,567"} {
+		if s := formatCount(count); s != expected {
+			t.Errorf("formatCount(%d) = %s, want %s", count, s, expected)
+		}
+	}
+}
+
+func TestBotEmptyConfig(t *testing.T) {
+	pullRequest := func(action string, merged bool) string {
+		return `
+			{
+				"action": "` + action + `",
+				"pull_request": {
+					"number": 14,
+					"merged": ` + fmt.Sprint(merged) + `,
+					"merge_commit_sha": "abc123",
+					"head": {
+						"sha": "abc123"
+					}
+				},
+				"repository": {
+					"name": "hellobot",
+					"owner": {
+						"login": "frozzare"
+					}
+				},
+				"sender": {
+					"login": "octocat"
+				}
+			}
+		`
+	}
+
+	repository := `
+		{
+			"action": "created",
+			"release": {
+				"tag_name": "v1.2.0"
+			},
+			"discussion": {
+				"node_id": "D_1",
+				"number": 1
+			},
+			"repository": {
+				"name": "hellobot",
+				"stargazers_count": 100,
+				"forks_count": 100,
+				"owner": {
+					"login": "frozzare"
+				}
+			},
+			"sender": {
+				"login": "octocat"
+			}
+		}
+	`
+
+	tests := []struct {
+		event string
+		body  string
+	}{
+		{"issues", issueOpenedPayload},
+		{"issue_comment", newCommentRequest("octocat", "/hello")},
+		{"pull_request", pullRequest("opened", false)},
+		{"pull_request", pullRequest("synchronize", false)},
+		{"pull_request", pullRequest("closed", true)},
+		{"release", strings.Replace(repository, "created", "published", 1)},
+		{"star", repository},
+		{"fork", repository},
+		{"discussion", repository},
+	}
+
+	for _, config := range []string{"\n", "# No config yet\n"} {
+		for _, test := range tests {
+			client := newClient(nil)
+			client.Repositories = &githubRepositories{config: config}
+
+			b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+			if err := b.Handle(newEventRequest(test.event, test.body)); !IsSkip(err) {
+				t.Fatalf("Expected %s with config %q to be skipped, got %v", test.event, config, err)
+			}
+		}
+	}
+}
+
+func TestBotUnhandledEvent(t *testing.T) {
+	client := newClient(nil)
+	repositories := &githubRepositories{config: `
+stale:
+  days_until_stale: 60
+`}
+	client.Repositories = repositories
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	for _, event := range []string{"installation", "check_run", "push"} {
+		if err := b.Handle(newEventRequest(event, issueOpenedPayload)); !IsSkip(err) {
+			t.Fatalf("Expected %s to be skipped, got %v", event, err)
+		}
+	}
+
+	if repositories.downloads != 0 {
+		t.Fatalf("Expected no config downloads, got %d", repositories.downloads)
+	}
+}
@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// fixLink represents issues fixed by a merged pull request that has not
+// been included in a release yet.
+type fixLink struct {
+	SHA         string `json:"sha"`
+	PullRequest int    `json:"pull_request"`
+	URL         string `json:"url"`
+	Issues      []int  `json:"issues"`
+}
+
+// merged comments on issues fixed by a merged pull request and tracks
+// the issues until the pull request is included in a release.
+func (b *Bot) merged(payload *Payload) error {
+	if err := b.prepare(payload); err != nil {
+		return err
+	}
+
+	fixes := b.config.Fixes
+	if len(fixes.MergedMessage) == 0 && !fixes.tracked() {
+		return skip("Fixes disabled")
+	}
+
+	issues, err := b.fixedIssues()
+	if err != nil {
+		return err
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+
+	pr := b.payload.PullRequest
+
+	if len(strings.TrimSpace(fixes.MergedMessage)) > 0 {
+		message := renderPlaceholders(fixes.MergedMessage, map[string]string{
+			"author": b.payload.Sender.Login,
+			"number": strconv.Itoa(pr.Number),
+			"url":    pr.HTMLURL,
+		})
+
+		for _, number := range issues {
+			unsubscribed, err := b.unsubscribed(number)
+			if err != nil {
+				return err
+			}
+
+			if unsubscribed {
+				continue
+			}
+
+			if err := b.comment(number, message); err != nil {
+				return err
+			}
+
+			if err := b.record(number, "fixed"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !fixes.tracked() || len(pr.MergeCommitSHA) == 0 {
+		return nil
+	}
+
+	links, err := b.fixLinks()
+	if err != nil {
+		return err
+	}
+
+	links = append(links, fixLink{SHA: pr.MergeCommitSHA, PullRequest: pr.Number, URL: pr.HTMLURL, Issues: issues})
+
+	return b.setFixLinks(links)
+}
+
+// fixedIssues returns the issues referenced with closing keywords in
+// the pull request body and commit messages.
+func (b *Bot) fixedIssues() ([]int, error) {
+	repository := b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name
+	issues := closingReferences(b.payload.PullRequest.Body, repository)
+
+	commits, err := b.listCommits(b.payload.PullRequest.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, commit := range commits {
+		for _, number := range closingReferences(commit.GetCommit().GetMessage(), repository) {
+			issues = appendNumber(issues, strconv.Itoa(number))
+		}
+	}
+
+	return issues, nil
+}
+
+// listCommits returns all commits in the pull request.
+func (b *Bot) listCommits(number int) ([]*github.RepositoryCommit, error) {
+	if b.client == nil || b.client.PullRequests == nil {
+		return nil, errors.New("No GitHub client")
+	}
+
+	var commits []*github.RepositoryCommit
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		page, res, err := b.client.PullRequests.ListCommits(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "github list pull request commits")
+		}
+
+		commits = append(commits, page...)
+
+		if res == nil || res.NextPage == 0 {
+			return commits, nil
+		}
+
+		opts.Page = res.NextPage
+	}
+}
+
+// releaseFixes comments on tracked issues fixed by pull requests that
+// are included in the release tag and stops tracking them.
+func (b *Bot) releaseFixes(tag string, placeholders map[string]string) error {
+	fixes := b.config.Fixes
+
+	links, err := b.fixLinks()
+	if err != nil {
+		return err
+	}
+
+	var pending []fixLink
+
+	for i, link := range links {
+		comparison, _, err := b.client.Repositories.CompareCommits(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, link.SHA, tag)
+		if err != nil {
+			pending = append(pending, links[i:]...)
+			return joinErrors(errors.Wrap(err, "github compare commits"), b.setFixLinks(pending))
+		}
+
+		// The merge commit is included when the tag is ahead of or identical to it.
+		if status := comparison.GetStatus(); status != "ahead" && status != "identical" {
+			pending = append(pending, link)
+			continue
+		}
+
+		message := renderPlaceholders(fixes.ReleasedMessage, placeholders)
+		message = renderPlaceholders(message, map[string]string{
+			"number": strconv.Itoa(link.PullRequest),
+		})
+
+		for j, number := range link.Issues {
+			if err := b.commentRelease(number, message, fixes.Labels); err != nil {
+				// Only the issues that are not commented are kept, so
+				// issues are not commented twice on the next release.
+				link.Issues = link.Issues[j:]
+				pending = append(pending, link)
+				pending = append(pending, links[i+1:]...)
+				return joinErrors(err, b.setFixLinks(pending))
+			}
+		}
+	}
+
+	return b.setFixLinks(pending)
+}
+
+// fixLinksKey returns the store key for the repository fix links.
+func (b *Bot) fixLinksKey() string {
+	return "fixes/" + b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name
+}
+
+// fixLinks returns the tracked fix links for the repository.
+func (b *Bot) fixLinks() ([]fixLink, error) {
+	buf, err := b.getStore().Get(b.fixLinksKey())
+	if err != nil {
+		return nil, errors.Wrap(err, "get fix links")
+	}
+
+	var links []fixLink
+
+	if buf == nil {
+		return links, nil
+	}
+
+	if err := json.Unmarshal(buf, &links); err != nil {
+		return nil, errors.Wrap(err, "unmarshal fix links")
+	}
+
+	return links, nil
+}
+
+// setFixLinks stores the tracked fix links for the repository.
+func (b *Bot) setFixLinks(links []fixLink) error {
+	buf, err := json.Marshal(links)
+	if err != nil {
+		return errors.Wrap(err, "marshal fix links")
+	}
+
+	return errors.Wrap(b.getStore().Set(b.fixLinksKey(), buf), "set fix links")
+}
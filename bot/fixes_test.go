@@ -0,0 +1,169 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestClosingReferences(t *testing.T) {
+	text := `Fixes #12, closes frozzare/hellobot#13 and resolved: https://github.com/frozzare/hellobot/issues/14.
+Fix frozzare/other#15, see #16 and fixes #12 again.`
+
+	expected := []int{12, 13, 14}
+	if numbers := closingReferences(text, "frozzare/hellobot"); !reflect.DeepEqual(numbers, expected) {
+		t.Fatalf("Expected %v, got %v", expected, numbers)
+	}
+}
+
+func TestBotFixes(t *testing.T) {
+	client := newClient(nil)
+	client.PullRequests = &githubPullRequests{commits: []string{"Add reactions\n\nCloses #13"}}
+	client.Repositories = &githubRepositories{
+		config: `
+fixes:
+  merged_message: "Fixed in #@{number}."
+  released_message: Released in @{tag}.
+  labels:
+    - released
+`,
+		compares: map[string]string{"abc123...v1.2.0": "ahead"},
+	}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	err := b.Handle(newEventRequest("pull_request", `
+		{
+			"action": "closed",
+			"pull_request": {
+				"number": 14,
+				"body": "Fixes #12",
+				"html_url": "https://github.com/frozzare/hellobot/pull/14",
+				"merged": true,
+				"merge_commit_sha": "abc123"
+			},
+			"repository": {
+				"name": "hellobot",
+				"owner": {
+					"login": "frozzare"
+				}
+			},
+			"sender": {
+				"login": "frozzare"
+			}
+		}
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues := client.Issues.(*githubIssues)
+
+	if !reflect.DeepEqual(issues.commented, []int{12, 13}) || issues.comments[0] != "Fixed in #14." {
+		t.Fatalf("Unexpected merged comments %v on %v", issues.comments, issues.commented)
+	}
+
+	release := func(tag string) error {
+		return b.Handle(newEventRequest("release", `
+			{
+				"action": "published",
+				"release": {
+					"tag_name": "`+tag+`"
+				},
+				"repository": {
+					"name": "hellobot",
+					"owner": {
+						"login": "frozzare"
+					}
+				},
+				"sender": {
+					"login": "frozzare"
+				}
+			}
+		`))
+	}
+
+	// The merge commit is not included in an older release.
+	if err := release("v1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(issues.commented) != 2 {
+		t.Fatalf("Expected no release comments, got %v", issues.comments)
+	}
+
+	if err := release("v1.2.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(issues.commented, []int{12, 13, 12, 13}) || issues.comments[2] != "Released in v1.2.0." {
+		t.Fatalf("Unexpected release comments %v on %v", issues.comments, issues.commented)
+	}
+
+	if !reflect.DeepEqual(issues.labels, []string{"released", "released"}) {
+		t.Fatalf("Unexpected labels %v", issues.labels)
+	}
+
+	// Released fixes are no longer tracked.
+	if err := release("v1.3.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(issues.commented) != 4 {
+		t.Fatalf("Expected no more comments, got %v", issues.comments)
+	}
+}
+
+func TestBotFixesProgress(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{
+		config: `
+fixes:
+  released_message: Released in @{tag}.
+`,
+		compares: map[string]string{"abc123...v1.2.0": "ahead", "def456...v1.2.0": "ahead"},
+	}
+
+	store := NewMemoryStore()
+	store.Set("fixes/frozzare/hellobot", []byte(`[{"sha":"abc123","pull_request":14,"issues":[12,13]},{"sha":"def456","pull_request":15,"issues":[16]}]`))
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: store}
+
+	release := func() error {
+		return b.Handle(newEventRequest("release", `
+			{
+				"action": "published",
+				"release": {
+					"tag_name": "v1.2.0"
+				},
+				"repository": {
+					"name": "hellobot",
+					"owner": {
+						"login": "frozzare"
+					}
+				},
+				"sender": {
+					"login": "frozzare"
+				}
+			}
+		`))
+	}
+
+	issues := client.Issues.(*githubIssues)
+	issues.failOn = 13
+
+	if err := release(); err == nil {
+		t.Fatal("Expected comment error")
+	}
+
+	// Issues that was commented before the failure are not commented again.
+	issues.failOn = 0
+
+	if err := release(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(issues.commented, []int{12, 13, 16}) {
+		t.Fatalf("Expected each issue to be commented once, got %v", issues.commented)
+	}
+}
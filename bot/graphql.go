@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// graphQLService sends GraphQL queries and mutations to the GitHub v4 API,
+// used for features that don't exist in the REST API, like discussions.
+type graphQLService struct {
+	client *github.Client
+}
+
+// graphQLResponse represents a GraphQL response.
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// endpoint returns the GraphQL endpoint, GitHub Enterprise Server has it at
+// `/api/graphql` and not next to the REST API at `/api/v3`.
+func (s *graphQLService) endpoint() string {
+	if !strings.HasSuffix(s.client.BaseURL.Path, "/api/v3/") {
+		return "graphql"
+	}
+
+	u := *s.client.BaseURL
+	u.Path = strings.TrimSuffix(u.Path, "v3/") + "graphql"
+
+	return u.String()
+}
+
+// Query sends the query with the variables and decodes the response data into v.
+func (s *graphQLService) Query(ctx context.Context, query string, variables map[string]interface{}, v interface{}) error {
+	req, err := s.client.NewRequest("POST", s.endpoint(), map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	var res graphQLResponse
+	if _, err := s.client.Do(ctx, req, &res); err != nil {
+		return err
+	}
+
+	if len(res.Errors) > 0 {
+		var messages []string
+		for _, e := range res.Errors {
+			messages = append(messages, e.Message)
+		}
+
+		return errors.Errorf("GraphQL: %s", strings.Join(messages, ", "))
+	}
+
+	if v == nil || len(res.Data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(res.Data, v)
+}
@@ -0,0 +1,186 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+// PrivateKey represents the GitHub app private key. Keys loaded from a file
+// or a directory are reloaded when the file changes, so keys can be rotated
+// without a restart.
+type PrivateKey struct {
+	mu      sync.RWMutex
+	data    []byte
+	version int
+	path    string
+	dir     string
+	file    string
+	modTime time.Time
+}
+
+// NewPrivateKey creates a private key from PEM data, the data can also be
+// base64 encoded like when it's given as an environment variable.
+func NewPrivateKey(data []byte) (*PrivateKey, error) {
+	data, err := decodeKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivateKey{data: data, version: 1}, nil
+}
+
+// NewPrivateKeyFromFile loads the private key from a PEM file.
+func NewPrivateKeyFromFile(path string) (*PrivateKey, error) {
+	k := &PrivateKey{path: path}
+
+	if _, err := k.Reload(); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// NewPrivateKeyFromDir loads the private key from the newest `.pem` file in
+// a directory, like a mounted secret.
+func NewPrivateKeyFromDir(dir string) (*PrivateKey, error) {
+	k := &PrivateKey{dir: dir}
+
+	if _, err := k.Reload(); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// decodeKey decodes base64 encoded keys and validates the PEM data.
+func decodeKey(data []byte) ([]byte, error) {
+	// Environment variables often have escaped newlines.
+	data = bytes.TrimSpace(bytes.Replace(data, []byte(`\n`), []byte("\n"), -1))
+
+	if !bytes.HasPrefix(data, []byte("-----BEGIN")) {
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding base64 private key")
+		}
+
+		data = bytes.TrimSpace(decoded)
+	}
+
+	if _, err := jwt.ParseRSAPrivateKeyFromPEM(data); err != nil {
+		return nil, errors.Wrap(err, "parsing private key")
+	}
+
+	return data, nil
+}
+
+// PEM returns the PEM data and the version of the key, the version is
+// increased each time the key is reloaded.
+func (k *PrivateKey) PEM() ([]byte, int) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.data, k.version
+}
+
+// keyFile returns the file the key is loaded from.
+func (k *PrivateKey) keyFile() (string, os.FileInfo, error) {
+	if len(k.path) > 0 {
+		info, err := os.Stat(k.path)
+		return k.path, info, errors.Wrap(err, "reading private key")
+	}
+
+	files, err := filepath.Glob(filepath.Join(k.dir, "*.pem"))
+	if err != nil {
+		return "", nil, errors.Wrap(err, "reading private key directory")
+	}
+
+	var newest string
+	var newestInfo os.FileInfo
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		if newestInfo == nil || info.ModTime().After(newestInfo.ModTime()) {
+			newest, newestInfo = file, info
+		}
+	}
+
+	if newestInfo == nil {
+		return "", nil, errors.Errorf("No .pem file in %s", k.dir)
+	}
+
+	return newest, newestInfo, nil
+}
+
+// Reload loads the key file again if it has changed and returns true when
+// the key was reloaded. Keys that are not loaded from files are never reloaded.
+func (k *PrivateKey) Reload() (bool, error) {
+	if len(k.path) == 0 && len(k.dir) == 0 {
+		return false, nil
+	}
+
+	file, info, err := k.keyFile()
+	if err != nil {
+		return false, err
+	}
+
+	k.mu.RLock()
+	changed := file != k.file || !info.ModTime().Equal(k.modTime)
+	k.mu.RUnlock()
+
+	if !changed {
+		return false, nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return false, errors.Wrap(err, "reading private key")
+	}
+
+	data, err = decodeKey(data)
+	if err != nil {
+		return false, errors.Wrap(err, file)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.data = data
+	k.version++
+	k.file = file
+	k.modTime = info.ModTime()
+
+	return true, nil
+}
+
+// Watch reloads the key every interval until done is closed. The reloaded
+// function is called when the key is reloaded or fails to reload, a key that
+// fails to reload is kept.
+func (k *PrivateKey) Watch(interval time.Duration, done <-chan struct{}, reloaded func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			changed, err := k.Reload()
+			if (changed || err != nil) && reloaded != nil {
+				reloaded(err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
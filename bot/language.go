@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// scripts maps languages to the unicode script that identifies them.
+// Japanese is checked before Chinese since Japanese text also contains Han.
+var scripts = []struct {
+	language string
+	tables   []*unicode.RangeTable
+}{
+	{"ja", []*unicode.RangeTable{unicode.Hiragana, unicode.Katakana}},
+	{"zh", []*unicode.RangeTable{unicode.Han}},
+	{"ko", []*unicode.RangeTable{unicode.Hangul}},
+	{"ru", []*unicode.RangeTable{unicode.Cyrillic}},
+	{"ar", []*unicode.RangeTable{unicode.Arabic}},
+	{"el", []*unicode.RangeTable{unicode.Greek}},
+	{"he", []*unicode.RangeTable{unicode.Hebrew}},
+	{"hi", []*unicode.RangeTable{unicode.Devanagari}},
+	{"th", []*unicode.RangeTable{unicode.Thai}},
+}
+
+// stopwords maps languages written with latin script to common words
+// that are not common in the other languages.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "it", "this", "that", "with", "when", "have", "not", "i", "you", "to", "of", "on", "but", "be", "are", "was", "thanks", "please"},
+	"es": {"el", "los", "las", "es", "y", "una", "con", "por", "pero", "cuando", "del", "al", "muy", "gracias", "hay", "puedo", "usted", "tengo", "esto", "cómo"},
+	"pt": {"não", "você", "é", "uma", "com", "obrigado", "são", "isso", "também", "mas", "ao", "dos", "das", "pelo", "tenho", "isto", "em", "o", "os"},
+	"fr": {"le", "les", "est", "et", "une", "avec", "pour", "mais", "quand", "du", "au", "très", "merci", "je", "vous", "ce", "cette", "pas", "dans", "sur"},
+	"de": {"der", "die", "das", "und", "ist", "ein", "eine", "mit", "für", "aber", "wenn", "nicht", "ich", "sie", "danke", "auf", "den", "dem", "zu", "bitte"},
+	"it": {"il", "gli", "è", "per", "della", "molto", "grazie", "sono", "questo", "non", "che", "di", "nel", "alla"},
+}
+
+// codeRegexp matches fenced and inline code, which is not natural language.
+var codeRegexp = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+
+// urlRegexp matches urls.
+var urlRegexp = regexp.MustCompile(`https?://\S+`)
+
+// detectLanguage returns the ISO 639-1 code of the text language or an
+// empty string when the language can't be detected.
+//
+// Languages with their own script are detected by the share of letters
+// in the script, other languages are detected by counting stopwords.
+func detectLanguage(text string) string {
+	text = codeRegexp.ReplaceAllString(text, " ")
+	text = urlRegexp.ReplaceAllString(text, " ")
+
+	letters := 0
+	counts := map[string]int{}
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		letters++
+
+		for _, s := range scripts {
+			if unicode.IsOneOf(s.tables, r) {
+				counts[s.language]++
+				break
+			}
+		}
+	}
+
+	if letters == 0 {
+		return ""
+	}
+
+	// Japanese text mixes kana with a lot of Han.
+	if counts["ja"] > 0 && float64(counts["ja"]+counts["zh"])/float64(letters) >= 0.3 {
+		return "ja"
+	}
+
+	for _, s := range scripts {
+		if float64(counts[s.language])/float64(letters) >= 0.3 {
+			return s.language
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+
+	scores := map[string]int{}
+
+	for _, word := range words {
+		for language, list := range stopwords {
+			for _, stopword := range list {
+				if word == stopword {
+					scores[language]++
+					break
+				}
+			}
+		}
+	}
+
+	best, tie := "", false
+	for _, language := range []string{"en", "es", "pt", "fr", "de", "it"} {
+		switch {
+		case best == "" || scores[language] > scores[best]:
+			best, tie = language, false
+		case scores[language] == scores[best]:
+			tie = true
+		}
+	}
+
+	if scores[best] < 2 || tie {
+		return ""
+	}
+
+	return best
+}
+
+// language returns the detected language of the issue or pull request.
+func (b *Bot) language() string {
+	if b.payload == nil {
+		return ""
+	}
+
+	if b.payload.IsPullRequest() {
+		return detectLanguage(b.payload.PullRequest.Title + "\n" + b.payload.PullRequest.Body)
+	}
+
+	return detectLanguage(b.payload.Issue.Title + "\n" + b.payload.Issue.Body)
+}
+
+// localized returns the item message variant for the language, keys like
+// `pt-BR` matches the language `pt`.
+func localized(item Item, language string) (Variant, bool) {
+	if len(language) == 0 {
+		return Variant{}, false
+	}
+
+	if v, ok := item.Languages[language]; ok {
+		return v, true
+	}
+
+	for key, v := range item.Languages {
+		if strings.HasPrefix(strings.ToLower(key), language+"-") {
+			return v, true
+		}
+	}
+
+	return Variant{}, false
+}
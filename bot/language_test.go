@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text     string
+		language string
+	}{
+		{"The bot is not working when I open an issue, please fix it.", "en"},
+		{"El bot no funciona cuando abro una issue con etiquetas, gracias por la ayuda.", "es"},
+		{"O bot não funciona quando eu abro uma issue, você pode ajudar? Obrigado.", "pt"},
+		{"Le bot ne marche pas quand je crée une issue avec des étiquettes, merci.", "fr"},
+		{"Der Bot funktioniert nicht, wenn ich ein Issue mit Labels erstelle. Danke!", "de"},
+		{"机器人在我创建问题时不工作，请帮忙修复。", "zh"},
+		{"イシューを作成するとボットが動作しません。", "ja"},
+		{"이슈를 만들 때 봇이 작동하지 않습니다.", "ko"},
+		{"Бот не работает, когда я создаю задачу.", "ru"},
+		{"```\npanic: runtime error\n```\nhttps://example.com/the/and/is", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if language := detectLanguage(test.text); language != test.language {
+			t.Errorf("detectLanguage(%q) = %q, want %q", test.text, language, test.language)
+		}
+	}
+}
+
+func TestBotLocalizedMessage(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+issue:
+  message: Hello @{author}
+  language_labels: true
+  languages:
+    es: Hola @{author}
+    pt-BR:
+      message: Olá @{author}
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	r := newRequest(`
+		{
+			"action": "opened",
+			"issue": {
+				"number": 1234,
+				"title": "O bot não funciona",
+				"body": "Quando eu abro uma issue o bot não responde, você pode ajudar? Obrigado."
+			},
+			"repository": {
+				"name": "Fredrik",
+				"owner": {
+					"login": "test"
+				}
+			},
+			"sender": {
+				"login": "octocat"
+			}
+		}
+	`)
+
+	if err := b.SayHello(r); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := client.Issues.(*githubIssues)
+
+	if expected := []string{"Olá octocat"}; !reflect.DeepEqual(issues.comments, expected) {
+		t.Fatalf("Expected comments %q, got %q", expected, issues.comments)
+	}
+
+	if expected := []string{"lang:pt"}; !reflect.DeepEqual(issues.labels, expected) {
+		t.Fatalf("Expected labels %v, got %v", expected, issues.labels)
+	}
+}
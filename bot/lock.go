@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// sweepLock locks issues and pull requests that have been closed for longer
+// than the configured days. The limit applies to the whole sweep, so locked is
+// how many issues and pull requests that earlier repositories in the sweep
+// have locked.
+func (b *Bot) sweepLock(locked int) ([]SweepAction, error) {
+	lock := b.config.Lock
+	if lock.Days <= 0 || locked >= lock.limit() {
+		return nil, nil
+	}
+
+	var actions []SweepAction
+
+	lockBefore := b.now().AddDate(0, 0, -lock.Days)
+	opts := &github.IssueListByRepoOptions{State: "closed", Sort: "updated", Direction: "asc"}
+
+	// Issues can't be listed by when they were closed, and comments on closed
+	// issues update them, so all closed issues are checked. Locking updates
+	// them too, so they are listed before any of them are locked.
+	var issues []*github.Issue
+
+	err := b.eachIssue(opts, func(issue *github.Issue) (bool, error) {
+		if issue.GetLocked() || !issue.GetClosedAt().Before(lockBefore) || hasLabel(issue, lock.ExemptLabels...) {
+			return true, nil
+		}
+
+		issues = append(issues, issue)
+
+		return locked+len(issues) < lock.limit(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		actions = append(actions, b.action(issue.GetNumber(), "lock", lock.DryRun))
+
+		if lock.DryRun {
+			continue
+		}
+
+		if err := b.lockIssue(issue.GetNumber(), renderMessage(lock.Message, issue.GetUser().GetLogin())); err != nil {
+			return actions, err
+		}
+	}
+
+	return actions, nil
+}
+
+// lockIssue comments the message and locks the issue or pull request.
+func (b *Bot) lockIssue(number int, message string) error {
+	if len(strings.TrimSpace(message)) > 0 {
+		_, _, err := b.client.Issues.CreateComment(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, &github.IssueComment{
+			Body: github.String(message),
+		})
+		if err != nil {
+			return errors.Wrap(err, "github create comment")
+		}
+	}
+
+	_, err := b.client.Issues.Lock(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number)
+	if err != nil {
+		return errors.Wrap(err, "github lock issue")
+	}
+
+	return b.record(number, "locked")
+}
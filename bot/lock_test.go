@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func newClosedIssue(number int, closed time.Time, labels ...string) *github.Issue {
+	issue := newStaleIssue(number, closed, labels...)
+	issue.State = github.String("closed")
+	issue.ClosedAt = &closed
+
+	return issue
+}
+
+func TestBotSweepLock(t *testing.T) {
+	now := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	locked := newClosedIssue(3, now.AddDate(-1, 0, -2))
+	locked.Locked = github.Bool(true)
+
+	// Comments on closed issues update them, but they are still locked.
+	commented := newClosedIssue(8, now.AddDate(-2, 0, 0))
+	updated := now.AddDate(0, 0, -1)
+	commented.UpdatedAt = &updated
+
+	b, issues := newSweepBot(`
+lock:
+  days: 365
+  limit: 2
+  message: Please open a new issue for related bugs.
+  exempt_labels:
+    - keep-open
+`, now,
+		commented,
+		newClosedIssue(1, now.AddDate(-2, 0, 0)),
+		newClosedIssue(2, now.AddDate(-2, 0, 0), "keep-open"),
+		locked,
+		newClosedIssue(4, now.AddDate(-1, 0, -1)),
+		newClosedIssue(5, now.AddDate(-1, 0, -1)),
+		newClosedIssue(6, now.AddDate(0, 0, -1)),
+		newStaleIssue(7, now.AddDate(-2, 0, 0)),
+	)
+
+	actions, err := b.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []SweepAction{
+		{Repository: "frozzare/hellobot", Number: 8, Action: "lock"},
+		{Repository: "frozzare/hellobot", Number: 1, Action: "lock"},
+	}
+
+	if !reflect.DeepEqual(actions, expected) {
+		t.Fatalf("Expected actions %v, got %v", expected, actions)
+	}
+
+	if !reflect.DeepEqual(issues.locked, []int{8, 1}) {
+		t.Fatalf("Expected issues 8 and 1 to be locked, got %v", issues.locked)
+	}
+
+	if len(issues.comments) != 2 {
+		t.Fatalf("Expected two comments, got %q", issues.comments)
+	}
+}
+
+func TestBotSweepLockLimit(t *testing.T) {
+	now := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	b, issues := newSweepBot(`
+lock:
+  days: 365
+  limit: 3
+`, now,
+		newClosedIssue(1, now.AddDate(-2, 0, 0)),
+		newClosedIssue(2, now.AddDate(-2, 0, 0)),
+	)
+
+	// Both repositories have the same issues, so only the limit stops the
+	// second repository from locking both of them.
+	apps := b.appClient.Apps.(*githubApps)
+	apps.repos[1].Private = github.Bool(false)
+
+	actions, err := b.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actions) != 3 || !reflect.DeepEqual(issues.locked, []int{1, 2, 1}) {
+		t.Fatalf("Expected three issues to be locked in the sweep, got %v", actions)
+	}
+}
+
+func TestBotSweepLockPages(t *testing.T) {
+	now := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	b, issues := newSweepBot(`
+lock:
+  days: 365
+`, now,
+		newClosedIssue(1, now.AddDate(-2, 0, 0)),
+		newClosedIssue(2, now.AddDate(-2, 0, 0)),
+		newClosedIssue(3, now.AddDate(-2, 0, 0)),
+	)
+
+	// Locked issues are moved last, which would skip issues on later pages.
+	issues.perPage = 1
+
+	if _, err := b.Sweep(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(issues.locked, []int{1, 2, 3}) {
+		t.Fatalf("Expected all issues to be locked, got %v", issues.locked)
+	}
+}
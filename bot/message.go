@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxIncludeDepth is the maximum depth of nested includes.
+const maxIncludeDepth = 5
+
+// includeRegexp matches `@{include path}` placeholders in messages.
+var includeRegexp = regexp.MustCompile(`@\{include\s+([^}\s]+)\s*\}`)
+
+// greeting returns the greeting message and labels for the item, replaced by
+// the override of the active schedule, like vacations. The item message is
+// only selected without an override message so the rotation doesn't advance
+// for messages that are never posted.
+func (b *Bot) greeting(item Item) (string, []string, error) {
+	var override Override
+	err := b.rule("schedule", func() (err error) {
+		override, err = b.override()
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var message string
+
+	if len(override.Message) > 0 || len(override.MessageFile) > 0 {
+		message, err = b.loadMessage(override.Message, override.MessageFile)
+	} else {
+		message, err = b.itemMessage(item)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	labels := appendUnique(nil, item.Labels...)
+
+	if len(override.Labels) > 0 {
+		labels = appendUnique(nil, override.Labels...)
+	}
+
+	return message, labels, nil
+}
+
+// itemMessage returns the message for the item in the detected language,
+// or selected from the message variants when there are any, with all
+// includes replaced.
+func (b *Bot) itemMessage(item Item) (string, error) {
+	if v, ok := localized(item, b.language()); ok {
+		return b.loadMessage(v.Message, v.MessageFile)
+	}
+
+	if len(item.Messages) == 0 {
+		return b.loadMessage(item.Message, item.MessageFile)
+	}
+
+	variant, err := b.selectVariant(item)
+	if err != nil {
+		return "", err
+	}
+
+	return b.loadMessage(variant.Message, variant.MessageFile)
+}
+
+// selectVariant selects one of the item message variants, by weighted random
+// selection or by rotating through the variants for each author.
+func (b *Bot) selectVariant(item Item) (Variant, error) {
+	switch item.Selection {
+	case "", "random":
+		total := 0
+		for _, v := range item.Messages {
+			total += v.weight()
+		}
+
+		n := b.random(total)
+		for _, v := range item.Messages {
+			if n < v.weight() {
+				return v, nil
+			}
+
+			n -= v.weight()
+		}
+
+		return item.Messages[len(item.Messages)-1], nil
+	case "rotate":
+		// Comments, like the hello command, are sent by someone else than
+		// the author, so the rotation uses the issue author when it's known.
+		author := strings.ToLower(b.payload.Sender.Login)
+		if len(b.payload.Issue.User.Login) > 0 {
+			author = strings.ToLower(b.payload.Issue.User.Login)
+		}
+
+		kind := "issue"
+		if b.payload.OnPullRequest() {
+			kind = "pull_request"
+		}
+
+		key := "messages/" + b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name + "/" + kind + "/" + author
+
+		buf, err := b.getStore().Get(key)
+		if err != nil {
+			return Variant{}, errors.Wrap(err, "get message rotation")
+		}
+
+		// First time authors starts at a position derived from the login.
+		var i int
+		if buf == nil {
+			h := fnv.New32a()
+			h.Write([]byte(author))
+			i = int(h.Sum32() % uint32(len(item.Messages)))
+		} else {
+			last, _ := strconv.Atoi(string(buf))
+			i = (last + 1) % len(item.Messages)
+		}
+
+		if err := b.getStore().Set(key, []byte(strconv.Itoa(i))); err != nil {
+			return Variant{}, errors.Wrap(err, "set message rotation")
+		}
+
+		return item.Messages[i], nil
+	default:
+		return Variant{}, errors.Errorf("Unknown selection %s", item.Selection)
+	}
+}
+
+// random returns a random number in [0, n) from the bot random source.
+func (b *Bot) random(n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rand == nil {
+		b.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return b.rand.Intn(n)
+}
+
+// loadMessage returns the message, loaded from the message file when
+// it's not empty, with all includes replaced.
+func (b *Bot) loadMessage(message, messageFile string) (string, error) {
+	if len(messageFile) == 0 {
+		return b.include(message, "", 0)
+	}
+
+	file, err := repositoryPath("", messageFile)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := b.downloadFile(file)
+	if err != nil {
+		return "", errors.Wrapf(err, "message file %s", file)
+	}
+
+	return b.include(string(data), path.Dir(file), 0)
+}
+
+// include replaces `@{include path}` placeholders with the content of
+// the files. Paths are relative to the directory of the including file,
+// or the repository root when starting with a slash.
+func (b *Bot) include(message, dir string, depth int) (string, error) {
+	var err error
+
+	message = includeRegexp.ReplaceAllStringFunc(message, func(match string) string {
+		if err != nil {
+			return match
+		}
+
+		if depth >= maxIncludeDepth {
+			err = errors.New("Too many nested includes")
+			return match
+		}
+
+		var file string
+		file, err = repositoryPath(dir, includeRegexp.FindStringSubmatch(match)[1])
+		if err != nil {
+			return match
+		}
+
+		var data []byte
+		data, err = b.downloadFile(file)
+		if err != nil {
+			err = errors.Wrapf(err, "include %s", file)
+			return match
+		}
+
+		var content string
+		content, err = b.include(string(data), path.Dir(file), depth+1)
+
+		return strings.TrimRight(content, "\n")
+	})
+
+	return message, err
+}
+
+// repositoryPath returns the path relative to the repository root.
+func repositoryPath(dir, file string) (string, error) {
+	if !strings.HasPrefix(file, "/") {
+		file = path.Join(dir, file)
+	}
+
+	file = strings.TrimPrefix(path.Clean("/"+file), "/")
+	if len(file) == 0 {
+		return "", errors.New("Invalid file path")
+	}
+
+	return file, nil
+}
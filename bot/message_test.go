@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestRepositoryPath(t *testing.T) {
+	tests := []struct {
+		dir  string
+		file string
+		path string
+	}{
+		{"", ".github/hello/issue.md", ".github/hello/issue.md"},
+		{".github/hello", "footer.md", ".github/hello/footer.md"},
+		{".github/hello", "../footer.md", ".github/footer.md"},
+		{".github/hello", "/docs/footer.md", "docs/footer.md"},
+		{"", "../../footer.md", "footer.md"},
+	}
+
+	for _, test := range tests {
+		if path, _ := repositoryPath(test.dir, test.file); path != test.path {
+			t.Errorf("repositoryPath(%q, %q) = %q, want %q", test.dir, test.file, path, test.path)
+		}
+	}
+}
+
+func TestBotMessageFile(t *testing.T) {
+	repos := &githubRepositories{
+		config: `
+issue:
+  message_file: .github/hello/issue.md
+`,
+		files: map[string]string{
+			".github/hello/issue.md":  "Hello @{author}\n\n@{include footer.md}\n",
+			".github/hello/footer.md": "@{include /.github/signature.md}\n",
+			".github/signature.md":    "~ Hellobot\n",
+		},
+	}
+
+	client := newClient(nil)
+	client.Repositories = repos
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), cache: newFileCache(time.Minute)}
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := client.Issues.(*githubIssues)
+	if len(issues.comments) != 1 || issues.comments[0] != "Hello test\n\n~ Hellobot\n" {
+		t.Fatalf("Unexpected comments %q", issues.comments)
+	}
+
+	if repos.downloads != 4 {
+		t.Fatalf("Expected 4 downloads, got %d", repos.downloads)
+	}
+
+	// Config and message files are cached.
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	if repos.downloads != 4 {
+		t.Fatalf("Expected files to be cached, got %d downloads", repos.downloads)
+	}
+}
+
+func TestBotMessageFileIncludeLoop(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{
+		config: `
+issue:
+  message: "@{include loop.md}"
+`,
+		files: map[string]string{
+			"loop.md": "@{include loop.md}",
+		},
+	}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err == nil {
+		t.Fatal("Expected error for include loop")
+	}
+}
+
+func TestVariantUnmarshal(t *testing.T) {
+	var item Item
+
+	if err := yaml.Unmarshal([]byte(`
+messages:
+  - Hello
+  - message: Hi
+    weight: 3
+  - message_file: hello.md
+`), &item); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Variant{{Message: "Hello"}, {Message: "Hi", Weight: 3}, {MessageFile: "hello.md"}}
+	if !reflect.DeepEqual(item.Messages, expected) {
+		t.Fatalf("Expected %v, got %v", expected, item.Messages)
+	}
+}
+
+func TestBotSelectVariantRandom(t *testing.T) {
+	item := Item{Messages: []Variant{{Message: "a"}, {Message: "b", Weight: 3}}}
+
+	selected := func(seed int64) []string {
+		b := &Bot{rand: rand.New(rand.NewSource(seed)), payload: &Payload{}}
+
+		var messages []string
+		for i := 0; i < 1000; i++ {
+			v, err := b.selectVariant(item)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			messages = append(messages, v.Message)
+		}
+
+		return messages
+	}
+
+	first := selected(1)
+	if !reflect.DeepEqual(first, selected(1)) {
+		t.Fatal("Expected same selection with same seed")
+	}
+
+	count := 0
+	for _, m := range first {
+		if m == "b" {
+			count++
+		}
+	}
+
+	if count < 650 || count > 850 {
+		t.Fatalf("Expected about 750 b messages, got %d", count)
+	}
+}
+
+func TestBotSelectVariantRotate(t *testing.T) {
+	item := Item{
+		Selection: "rotate",
+		Messages:  []Variant{{Message: "a"}, {Message: "b"}, {Message: "c"}},
+	}
+
+	b := &Bot{payload: &Payload{}, store: NewMemoryStore()}
+	b.payload.Sender.Login = "octocat"
+
+	var messages []string
+	for i := 0; i < 6; i++ {
+		v, err := b.selectVariant(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		messages = append(messages, v.Message)
+	}
+
+	for i := 1; i < len(messages); i++ {
+		if messages[i] == messages[i-1] {
+			t.Fatalf("Same message twice in a row: %v", messages)
+		}
+	}
+
+	if messages[0] != messages[3] || messages[1] != messages[4] || messages[2] != messages[5] {
+		t.Fatalf("Expected messages to rotate, got %v", messages)
+	}
+}
+
+func TestBotSelectVariantRotateComment(t *testing.T) {
+	item := Item{
+		Selection: "rotate",
+		Messages:  []Variant{{Message: "a"}, {Message: "b"}, {Message: "c"}},
+	}
+
+	store := NewMemoryStore()
+
+	b := &Bot{payload: &Payload{}, store: store}
+	b.payload.Sender.Login = "frozzare"
+	b.payload.Issue.Number = 12
+	b.payload.Issue.User.Login = "octocat"
+	b.payload.Issue.PullRequest = &struct {
+		URL string `json:"url"`
+	}{}
+
+	if _, err := b.selectVariant(item); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rotation is kept for the pull request author, not the commenter.
+	keys, err := store.Keys("messages/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"messages///pull_request/octocat"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("Expected keys %v, got %v", expected, keys)
+	}
+}
@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"expvar"
+	"strconv"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+var (
+	// deliveriesProcessed counts the handled webhook deliveries.
+	deliveriesProcessed = expvar.NewInt("hellobot.deliveries")
+
+	// deliveryOutcomes counts the handled webhook deliveries by outcome.
+	deliveryOutcomes = expvar.NewMap("hellobot.outcomes")
+)
+
+// outcome returns the outcome of a handled webhook delivery, expected skips
+// like disabled features are not counted as errors.
+func outcome(err error) string {
+	if err == nil {
+		return "ok"
+	}
+
+	if s, ok := errors.Cause(err).(*skipError); ok {
+		if s.duplicate {
+			return "duplicate"
+		}
+
+		return "skipped"
+	}
+
+	return "error"
+}
+
+// Stats returns the sizes of the bot caches.
+func (b *Bot) Stats() map[string]int {
+	b.mu.Lock()
+	clients := len(b.clients)
+	b.mu.Unlock()
+
+	files := 0
+	if b.cache != nil {
+		files = b.cache.len()
+	}
+
+	return map[string]int{
+		"files":   files,
+		"clients": clients,
+	}
+}
+
+// CheckKey checks that the app private key can be used to sign the app JWT,
+// like the app client does.
+func (b *Bot) CheckKey() error {
+	data, err := b.privateKey()
+	if err != nil {
+		return err
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		return errors.Wrap(err, "parsing private key")
+	}
+
+	claims := &jwt.StandardClaims{
+		IssuedAt:  b.now().Unix(),
+		ExpiresAt: b.now().Add(time.Minute).Unix(),
+		Issuer:    strconv.Itoa(b.id),
+	}
+
+	_, err = jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+
+	return errors.Wrap(err, "signing app jwt")
+}
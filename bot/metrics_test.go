@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"context"
+	"expvar"
+	"testing"
+)
+
+func TestBotCheckKey(t *testing.T) {
+	key, err := NewPrivateKey(newKeyPEM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewBot(1234, key).CheckKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewBot(1234, nil).CheckKey(); err == nil {
+		t.Fatal("Expected error for missing key")
+	}
+}
+
+func TestBotOutcomes(t *testing.T) {
+	client := newClient(nil)
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	count := func(outcome string) int64 {
+		if v, ok := deliveryOutcomes.Get(outcome).(*expvar.Int); ok {
+			return v.Value()
+		}
+
+		return 0
+	}
+
+	before := map[string]int64{}
+	for _, outcome := range []string{"ok", "skipped", "duplicate", "error"} {
+		before[outcome] = count(outcome)
+	}
+
+	deliver := func(sender string) {
+		r := newEventRequest("issues", `
+			{
+				"action": "edited",
+				"issue": {
+					"number": 12
+				},
+				"repository": {
+					"name": "hellobot",
+					"owner": {
+						"login": "frozzare"
+					}
+				},
+				"sender": {
+					"login": "`+sender+`",
+					"type": "Bot"
+				}
+			}
+		`)
+		r.Header.Set("X-GitHub-Delivery", "delivery-"+sender)
+		b.Handle(r)
+	}
+
+	// Activity from bots is skipped.
+	deliver("dependabot")
+	deliver("dependabot")
+
+	expected := map[string]int64{"ok": 0, "skipped": 1, "duplicate": 1, "error": 0}
+	for outcome, n := range expected {
+		if c := count(outcome) - before[outcome]; c != n {
+			t.Fatalf("Expected %d %s outcomes, got %d", n, outcome, c)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"strconv"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// milestone creates an issue that celebrates when the repository reaches
+// one of the configured star or fork counts for the first time.
+func (b *Bot) milestone(event string, payload *MilestonePayload) error {
+	// Only new stars is handled, fork events has no action.
+	if event == "star" && payload.Action != "created" {
+		return skip("Only created action is handled")
+	}
+
+	if err := b.prepare(payload.payload()); err != nil {
+		return err
+	}
+
+	milestones := b.config.Milestones
+
+	kind, count, counts := "stars", payload.Repository.StargazersCount, milestones.Stars
+	if event == "fork" {
+		kind, count, counts = "forks", payload.Repository.ForksCount, milestones.Forks
+	}
+
+	// Webhooks can be missed or arrive when the count has already passed the
+	// milestone, so the highest milestone reached is celebrated.
+	reached := 0
+	for _, c := range counts {
+		if count >= c && c > reached {
+			reached = c
+		}
+	}
+
+	if reached == 0 {
+		return skip("No milestone reached")
+	}
+
+	// Stars can be removed and added again, so milestones are only celebrated once.
+	key := "milestones/" + b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name + "/" + kind + "/" + strconv.Itoa(reached)
+
+	buf, err := b.getStore().Get(key)
+	if err != nil {
+		return errors.Wrap(err, "get milestone")
+	}
+
+	if buf != nil {
+		return skip("Milestone already celebrated")
+	}
+
+	placeholders := map[string]string{
+		"author": b.payload.Sender.Login,
+		"count":  formatCount(reached),
+		"kind":   kind,
+	}
+
+	issue := &github.IssueRequest{
+		Title: github.String(renderPlaceholders(milestones.title(), placeholders)),
+		Body:  github.String(renderPlaceholders(milestones.Message, placeholders)),
+	}
+
+	if len(milestones.Labels) > 0 {
+		issue.Labels = &milestones.Labels
+	}
+
+	if _, _, err := b.client.Issues.Create(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, issue); err != nil {
+		return errors.Wrap(err, "github create issue")
+	}
+
+	return errors.Wrap(b.getStore().Set(key, []byte("1")), "set milestone")
+}
+
+// formatCount formats the count with thousands separators, like 1,000.
+func formatCount(count int) string {
+	s := strconv.Itoa(count)
+
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+
+	return s
+}
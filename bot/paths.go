@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"path"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// matchPath reports whether the file name matches the glob pattern.
+//
+// Patterns without a slash matches the base name in any directory,
+// `**` matches zero or more directories and a trailing slash matches
+// everything inside the directory.
+func matchPath(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	name = strings.TrimPrefix(name, "/")
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+
+	if !strings.Contains(pattern, "/") && pattern != "**" {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches pattern segments against path segments.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// listFiles returns all file names changed in the pull request.
+func (b *Bot) listFiles(number int) ([]string, error) {
+	if b.client == nil || b.client.PullRequests == nil {
+		return nil, errors.New("No GitHub client")
+	}
+
+	var names []string
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		files, res, err := b.client.PullRequests.ListFiles(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "github list pull request files")
+		}
+
+		for _, file := range files {
+			names = append(names, file.GetFilename())
+		}
+
+		if res == nil || res.NextPage == 0 {
+			break
+		}
+
+		opts.Page = res.NextPage
+	}
+
+	return names, nil
+}
+
+// matchPaths returns labels and message paragraphs from the path rules
+// that matches any of the files changed in the pull request.
+func (b *Bot) matchPaths(rules []PathRule, number int) ([]string, []string, error) {
+	files, err := b.listFiles(number)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var labels []string
+	var messages []string
+
+	for _, rule := range rules {
+		if !matchAny(rule.Patterns, files) {
+			continue
+		}
+
+		labels = appendUnique(labels, rule.Labels...)
+
+		if m := strings.TrimSpace(rule.Message); len(m) > 0 {
+			messages = append(messages, m)
+		}
+	}
+
+	return labels, messages, nil
+}
+
+// matchAny reports whether any of the files matches any of the patterns.
+func matchAny(patterns, files []string) bool {
+	for _, pattern := range patterns {
+		for _, file := range files {
+			if matchPath(pattern, file) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// appendUnique appends values that don't already exists in the slice.
+func appendUnique(slice []string, values ...string) []string {
+	for _, value := range values {
+		exists := false
+
+		for _, s := range slice {
+			if strings.ToLower(s) == strings.ToLower(value) {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			slice = append(slice, value)
+		}
+	}
+
+	return slice
+}
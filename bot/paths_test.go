@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "bot/bot.go", true},
+		{"*.go", "bot/bot.yml", false},
+		{"docs/**", "docs/index.md", true},
+		{"docs/**", "docs/a/b/c.md", true},
+		{"docs/**", "site/docs/index.md", false},
+		{"docs/", "docs/index.md", true},
+		{"/vendor/**", "vendor/github.com/pkg/errors/errors.go", true},
+		{"api/*.proto", "api/hello.proto", true},
+		{"api/*.proto", "api/v1/hello.proto", false},
+		{"api/**/*.proto", "api/v1/hello.proto", true},
+		{"api/**/*.proto", "api/hello.proto", true},
+		{"**", "bot/bot.go", true},
+	}
+
+	for _, test := range tests {
+		if match := matchPath(test.pattern, test.name); match != test.match {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", test.pattern, test.name, match, test.match)
+		}
+	}
+}
+
+func TestBotPathRules(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+pull_request:
+  message: Hello @{author}
+  labels:
+    - pr
+  paths:
+    - patterns:
+       - api/*.proto
+      message: Remember to run codegen.
+    - patterns:
+       - docs/**
+      labels:
+        - documentation
+    - patterns:
+       - "*.go"
+      labels:
+        - go
+        - pr
+    - patterns:
+       - vendor/**
+      labels:
+        - dependencies
+`}
+	client.PullRequests = &githubPullRequests{files: [][]string{
+		{"bot/bot.go", "readme.md"},
+		{"docs/index.md", "api/hello.proto"},
+	}}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	if err := b.SayHello(newRequest(pullRequestOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := client.Issues.(*githubIssues)
+
+	if len(issues.comments) != 1 || issues.comments[0] != "Hello octocat\n\nRemember to run codegen." {
+		t.Fatalf("Unexpected comments %q", issues.comments)
+	}
+
+	if expected := []string{"pr", "documentation", "go"}; !reflect.DeepEqual(issues.labels, expected) {
+		t.Fatalf("Expected labels %v, got %v", expected, issues.labels)
+	}
+}
@@ -4,31 +4,158 @@ package bot
 type Payload struct {
 	Action string `json:"action"`
 	Issue  struct {
-		Number int `json:"number"`
-		Labels []struct {
-			Name string `json:"string"`
-		} `json:"labels"`
+		Number      int     `json:"number"`
+		Title       string  `json:"title"`
+		Body        string  `json:"body"`
+		Labels      []Label `json:"labels"`
+		User        User    `json:"user"`
+		PullRequest *struct {
+			URL string `json:"url"`
+		} `json:"pull_request"`
 	} `json:"issue"`
 	PullRequest struct {
-		Number int `json:"number"`
+		Number         int     `json:"number"`
+		Title          string  `json:"title"`
+		Body           string  `json:"body"`
+		Labels         []Label `json:"labels"`
+		HTMLURL        string  `json:"html_url"`
+		Merged         bool    `json:"merged"`
+		MergeCommitSHA string  `json:"merge_commit_sha"`
+		Head           struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
 	} `json:"pull_request"`
-	Repository struct {
-		DefaultBranch string `json:"default_branch"`
-		Name          string `json:"name"`
-		Owner         struct {
-			Login string `json:"login"`
-		} `json:"owner"`
-		Private bool `json:"private"`
-	} `json:"repository"`
-	Sender struct {
-		Login string `json:"login"`
-	} `json:"sender"`
-	Installation struct {
-		ID int `json:"id"`
-	} `json:"installation"`
+	Comment struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+		User User   `json:"user"`
+	} `json:"comment"`
+	Label        Label        `json:"label"`
+	Repository   Repository   `json:"repository"`
+	Sender       User         `json:"sender"`
+	Installation Installation `json:"installation"`
+}
+
+// Repository represents the repository in a payload.
+type Repository struct {
+	DefaultBranch   string `json:"default_branch"`
+	Name            string `json:"name"`
+	FullName        string `json:"full_name"`
+	Owner           User   `json:"owner"`
+	Private         bool   `json:"private"`
+	StargazersCount int    `json:"stargazers_count"`
+	ForksCount      int    `json:"forks_count"`
+}
+
+// User represents a user in a payload.
+type User struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// Installation represents the app installation in a payload.
+type Installation struct {
+	ID int `json:"id"`
+}
+
+// Label represents a label in the payload.
+type Label struct {
+	Name string `json:"name"`
 }
 
 // IsPullRequest returns true when the payload it's a pull request payload.
 func (p *Payload) IsPullRequest() bool {
 	return p.PullRequest.Number > 0 && p.Issue.Number == 0
 }
+
+// OnPullRequest returns true when the payload is about a pull request, also
+// for comments on pull requests that are sent as issue comments.
+func (p *Payload) OnPullRequest() bool {
+	return p.IsPullRequest() || p.Issue.PullRequest != nil
+}
+
+// Labels returns the labels of the issue or pull request.
+func (p *Payload) Labels() []Label {
+	if p.IsPullRequest() {
+		return p.PullRequest.Labels
+	}
+
+	return p.Issue.Labels
+}
+
+// DiscussionPayload struct of GitHub webhooks for discussions.
+type DiscussionPayload struct {
+	Action     string `json:"action"`
+	Discussion struct {
+		NodeID string `json:"node_id"`
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+	} `json:"discussion"`
+	Repository   Repository   `json:"repository"`
+	Sender       User         `json:"sender"`
+	Installation Installation `json:"installation"`
+}
+
+// payload returns the discussion as an issue payload, discussions are
+// handled like issues when messages are selected.
+func (p *DiscussionPayload) payload() *Payload {
+	payload := &Payload{
+		Action:       p.Action,
+		Repository:   p.Repository,
+		Sender:       p.Sender,
+		Installation: p.Installation,
+	}
+
+	payload.Issue.Title = p.Discussion.Title
+	payload.Issue.Body = p.Discussion.Body
+
+	return payload
+}
+
+// MilestonePayload struct of GitHub webhooks for stars and forks.
+type MilestonePayload struct {
+	Action       string       `json:"action"`
+	Repository   Repository   `json:"repository"`
+	Sender       User         `json:"sender"`
+	Installation Installation `json:"installation"`
+}
+
+// payload returns the milestone payload as a payload without issue.
+func (p *MilestonePayload) payload() *Payload {
+	return &Payload{
+		Action:       p.Action,
+		Repository:   p.Repository,
+		Sender:       p.Sender,
+		Installation: p.Installation,
+	}
+}
+
+// ReleasePayload struct of GitHub webhooks for releases.
+type ReleasePayload struct {
+	Action  string `json:"action"`
+	Release struct {
+		TagName    string `json:"tag_name"`
+		Name       string `json:"name"`
+		Body       string `json:"body"`
+		HTMLURL    string `json:"html_url"`
+		Draft      bool   `json:"draft"`
+		Prerelease bool   `json:"prerelease"`
+	} `json:"release"`
+	Repository   Repository   `json:"repository"`
+	Sender       User         `json:"sender"`
+	Installation Installation `json:"installation"`
+}
+
+// payload returns the release payload as a payload without issue.
+func (p *ReleasePayload) payload() *Payload {
+	return &Payload{
+		Action:       p.Action,
+		Repository:   p.Repository,
+		Sender:       p.Sender,
+		Installation: p.Installation,
+	}
+}
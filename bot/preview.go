@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// PreviewRequest represents a sample event to preview a config with.
+type PreviewRequest struct {
+	Config string `json:"config"`
+	Event  string `json:"event"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	Author string `json:"author"`
+}
+
+// PreviewResult represents what the bot would do for the sample event.
+type PreviewResult struct {
+	Errors    []string `json:"errors"`
+	Comment   string   `json:"comment"`
+	Labels    []string `json:"labels"`
+	Reaction  string   `json:"reaction"`
+	Reviewers []string `json:"reviewers"`
+	Assignees []string `json:"assignees"`
+}
+
+// preview records the actions instead of sending them to GitHub.
+type preview struct {
+	config []byte
+	result *PreviewResult
+}
+
+// Preview runs the opened issue or pull request handling for the sample event
+// with the config, without GitHub, and returns what the bot would do.
+func Preview(req PreviewRequest) *PreviewResult {
+	result := &PreviewResult{Errors: []string{}, Labels: []string{}, Reviewers: []string{}, Assignees: []string{}}
+
+	config, err := parseConfig([]byte(req.Config))
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+
+	if config != nil {
+		result.Errors = append(result.Errors, config.problems()...)
+	}
+
+	if len(req.Author) == 0 {
+		req.Author = "octocat"
+	}
+
+	payload := &Payload{Action: "opened"}
+	payload.Repository.Owner.Login = "octocat"
+	payload.Repository.Name = "hello-world"
+	payload.Sender.Login = req.Author
+
+	switch req.Event {
+	case "issues", "issue", "":
+		payload.Issue.Number = 1
+		payload.Issue.Title = req.Title
+		payload.Issue.Body = req.Body
+	case "pull_request":
+		payload.PullRequest.Number = 1
+		payload.PullRequest.Title = req.Title
+		payload.PullRequest.Body = req.Body
+	default:
+		result.Errors = append(result.Errors, "Unknown event "+req.Event)
+		return result
+	}
+
+	p := &preview{config: []byte(req.Config), result: result}
+
+	b := &Bot{
+		ctx:   context.Background(),
+		store: NewMemoryStore(),
+		clients: map[int]*githubClient{
+			payload.Installation.ID: {
+				Issues:       &previewIssues{p},
+				PullRequests: &previewPullRequests{p},
+				Reactions:    &previewReactions{p},
+				Repositories: &previewRepositories{p},
+			},
+		},
+	}
+
+	if err := b.sayHello(payload); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	return result
+}
+
+type previewIssues struct{ *preview }
+
+func (p *previewIssues) AddAssignees(ctx context.Context, owner, repo string, number int, assignees []string) (*github.Issue, *github.Response, error) {
+	p.result.Assignees = append(p.result.Assignees, assignees...)
+	return nil, nil, nil
+}
+
+func (p *previewIssues) AddLabelsToIssue(ctx context.Context, owner, repo string, number int, labels []string) ([]*github.Label, *github.Response, error) {
+	p.result.Labels = append(p.result.Labels, labels...)
+	return nil, nil, nil
+}
+
+func (p *previewIssues) Create(ctx context.Context, owner, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return nil, nil, errors.New("Not supported in preview")
+}
+
+func (p *previewIssues) CreateComment(ctx context.Context, owner, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	p.result.Comment = comment.GetBody()
+	return nil, nil, nil
+}
+
+func (p *previewIssues) Edit(ctx context.Context, owner, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return nil, nil, errors.New("Not supported in preview")
+}
+
+func (p *previewIssues) ListByRepo(ctx context.Context, owner, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	return nil, &github.Response{}, nil
+}
+
+func (p *previewIssues) Lock(ctx context.Context, owner, repo string, number int) (*github.Response, error) {
+	return nil, errors.New("Not supported in preview")
+}
+
+func (p *previewIssues) RemoveLabelForIssue(ctx context.Context, owner, repo string, number int, label string) (*github.Response, error) {
+	return nil, errors.New("Not supported in preview")
+}
+
+type previewPullRequests struct{ *preview }
+
+func (p *previewPullRequests) Get(ctx context.Context, owner, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	return &github.PullRequest{}, nil, nil
+}
+
+func (p *previewPullRequests) ListCommits(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	return nil, &github.Response{}, nil
+}
+
+func (p *previewPullRequests) ListFiles(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+	return nil, &github.Response{}, nil
+}
+
+func (p *previewPullRequests) RequestReviewers(ctx context.Context, owner, repo string, number int, reviewers github.ReviewersRequest) (*github.PullRequest, *github.Response, error) {
+	p.result.Reviewers = append(p.result.Reviewers, reviewers.Reviewers...)
+
+	for _, team := range reviewers.TeamReviewers {
+		p.result.Reviewers = append(p.result.Reviewers, owner+"/"+team)
+	}
+
+	return nil, nil, nil
+}
+
+type previewReactions struct{ *preview }
+
+func (p *previewReactions) CreateIssueCommentReaction(ctx context.Context, owner, repo string, id int64, content string) (*github.Reaction, *github.Response, error) {
+	return nil, nil, nil
+}
+
+func (p *previewReactions) CreateIssueReaction(ctx context.Context, owner, repo string, number int, content string) (*github.Reaction, *github.Response, error) {
+	p.result.Reaction = content
+	return nil, nil, nil
+}
+
+type previewRepositories struct{ *preview }
+
+func (p *previewRepositories) CompareCommits(ctx context.Context, owner, repo, base, head string) (*github.CommitsComparison, *github.Response, error) {
+	return nil, nil, errors.New("Not supported in preview")
+}
+
+func (p *previewRepositories) CreateStatus(ctx context.Context, owner, repo, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+	return nil, nil, errors.New("Not supported in preview")
+}
+
+// DownloadContents returns the config, other files like message files
+// don't exist in the preview.
+func (p *previewRepositories) DownloadContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (io.ReadCloser, error) {
+	if path != ".hello.yml" {
+		return nil, errors.Errorf("No file named %s found in the preview", path)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(p.config)), nil
+}
+
+func (p *previewRepositories) GetPermissionLevel(ctx context.Context, owner, repo, user string) (*github.RepositoryPermissionLevel, *github.Response, error) {
+	return &github.RepositoryPermissionLevel{Permission: github.String("none")}, nil, nil
+}
+
+func (p *previewRepositories) IsCollaborator(ctx context.Context, owner, repo, user string) (bool, *github.Response, error) {
+	return false, nil, nil
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	config := `
+pull_request:
+  message: Thanks @{author}!
+  labels:
+    - review
+  language_labels: true
+  reaction: rocket
+  reviewers:
+    users:
+      - frozzare
+      - monalisa
+`
+
+	result := Preview(PreviewRequest{Config: config, Event: "pull_request", Title: "Fix typo", Body: "This is the fix for the typo and it is not big"})
+
+	expected := &PreviewResult{
+		Errors:    []string{},
+		Comment:   "Thanks octocat!",
+		Labels:    []string{"review", "lang:en"},
+		Reaction:  "rocket",
+		Reviewers: []string{"frozzare", "monalisa"},
+		Assignees: []string{},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestPreviewErrors(t *testing.T) {
+	tests := map[string]PreviewRequest{
+		"unmarshal yaml: yaml: line 1: did not find expected node content": {Config: "issue: [", Event: "issues"},
+		"Unknown event push": {Config: "issue:\n  message: Hi", Event: "push"},
+		"Item disabled":      {Config: "issue:\n  disabled: true", Event: "issues"},
+	}
+
+	for expected, req := range tests {
+		result := Preview(req)
+		if len(result.Errors) != 1 || result.Errors[0] != expected {
+			t.Errorf("Expected %q, got %v", expected, result.Errors)
+		}
+	}
+
+	result := Preview(PreviewRequest{Config: "issue:\n  message: Hi\n  reaction: smile", Event: "issues"})
+	if !reflect.DeepEqual(result.Errors, []string{"issue: unknown reaction smile", "Unknown reaction smile"}) {
+		t.Fatalf("Unexpected errors %v", result.Errors)
+	}
+}
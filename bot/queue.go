@@ -0,0 +1,186 @@
+package bot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/frozzare/hellobot/trace"
+	"github.com/pkg/errors"
+)
+
+// ErrQueueFull is returned when a webhook can't be queued since the queue is full.
+var ErrQueueFull = errors.New("Queue is full")
+
+// ErrInvalidSignature is returned when a webhook signature doesn't match the
+// webhook secret.
+var ErrInvalidSignature = errors.New("Invalid webhook signature")
+
+// job represents a queued webhook delivery. Redeliveries are handled even
+// if the delivery has been handled before and the result is sent to handled.
+type job struct {
+	ctx        context.Context
+	event      string
+	delivery   string
+	body       []byte
+	redelivery bool
+	handled    chan error
+}
+
+// Queue handles webhook deliveries in the background, one at the time in the
+// order they are received, so GitHub gets a response without waiting.
+type Queue struct {
+	bot    *Bot
+	jobs   chan job
+	done   func(error)
+	secret string
+}
+
+// NewQueue creates a new queue with room for size deliveries and starts
+// handling them. The done function is called with the result of each delivery.
+func NewQueue(b *Bot, size int, done func(error)) *Queue {
+	q := &Queue{bot: b, jobs: make(chan job, size), done: done}
+
+	go q.run()
+
+	return q
+}
+
+// Enqueue reads the webhook request and queues the delivery. The delivery is
+// handled in a span that is a child of the span of the received request.
+func (q *Queue) Enqueue(r *http.Request) error {
+	event := r.Header.Get("X-GitHub-Event")
+	delivery := r.Header.Get("X-GitHub-Delivery")
+
+	ctx, span := trace.Start(context.Background(), "webhook.receive", "github.event", event, "github.delivery", delivery)
+	defer span.Finish()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		span.SetError(err)
+		return errors.Wrap(err, "reading payload")
+	}
+
+	if !q.verify(r, body) {
+		span.SetError(ErrInvalidSignature)
+		return ErrInvalidSignature
+	}
+
+	select {
+	case q.jobs <- job{ctx: ctx, event: event, delivery: delivery, body: body}:
+		return nil
+	default:
+		span.SetError(ErrQueueFull)
+		deliveryOutcomes.Add("dropped", 1)
+		return ErrQueueFull
+	}
+}
+
+// Redeliver queues the recorded webhook delivery to be handled again and
+// returns the delivery with the new outcome when it has been handled. The
+// delivery is handled by the queue, like deliveries from GitHub.
+func (q *Queue) Redeliver(id string) (*Delivery, error) {
+	delivery, err := q.bot.Delivery(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(delivery.Payload) == 0 {
+		return nil, errors.Errorf("Delivery %s has no payload", id)
+	}
+
+	ctx, span := trace.Start(context.Background(), "webhook.redeliver", "github.event", delivery.Event, "github.delivery", id)
+	defer span.Finish()
+
+	handled := make(chan error, 1)
+
+	select {
+	case q.jobs <- job{ctx: ctx, event: delivery.Event, delivery: id, body: delivery.Payload, redelivery: true, handled: handled}:
+	default:
+		span.SetError(ErrQueueFull)
+		return nil, ErrQueueFull
+	}
+
+	// The outcome is recorded with the delivery.
+	<-handled
+
+	return q.bot.Delivery(id)
+}
+
+// SetSecret sets the webhook secret, deliveries must be signed with it.
+func (q *Queue) SetSecret(secret string) {
+	q.secret = secret
+}
+
+// verify verifies the signature of the webhook body, the SHA-256 signature
+// is used when GitHub sends it.
+func (q *Queue) verify(r *http.Request, body []byte) bool {
+	if len(q.secret) == 0 {
+		return true
+	}
+
+	prefix, fn := "sha256=", sha256.New
+	signature := r.Header.Get("X-Hub-Signature-256")
+
+	if len(signature) == 0 {
+		prefix, fn = "sha1=", sha1.New
+		signature = r.Header.Get("X-Hub-Signature")
+	}
+
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(fn, []byte(q.secret))
+	mac.Write(body)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// Len returns the number of queued deliveries.
+func (q *Queue) Len() int {
+	return len(q.jobs)
+}
+
+// Saturated returns true when the queue is at least 90 percent full.
+func (q *Queue) Saturated() bool {
+	return len(q.jobs)*10 >= cap(q.jobs)*9
+}
+
+// handle handles the queued delivery and recovers from panics so the queue
+// keeps running. Panics in the event handlers are already recovered by the
+// bot and recorded with the delivery.
+func (q *Queue) handle(j job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("Panic handling delivery %s: %v", j.delivery, r)
+		}
+	}()
+
+	return q.bot.handleEvent(j.ctx, j.event, j.delivery, j.body, j.redelivery)
+}
+
+// run handles the queued deliveries.
+func (q *Queue) run() {
+	for j := range q.jobs {
+		err := q.handle(j)
+
+		if q.done != nil {
+			q.done(err)
+		}
+
+		if j.handled != nil {
+			j.handled <- err
+		}
+	}
+}
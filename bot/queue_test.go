@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	client := newClient(nil)
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	done := make(chan error)
+	q := &Queue{bot: b, jobs: make(chan job, 1), done: func(err error) { done <- err }}
+
+	if err := q.Enqueue(newEventRequest("issues", issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !q.Saturated() {
+		t.Fatal("Expected queue to be saturated")
+	}
+
+	if err := q.Enqueue(newEventRequest("issues", issueOpenedPayload)); err != ErrQueueFull {
+		t.Fatalf("Expected queue full error, got %v", err)
+	}
+
+	processed := deliveriesProcessed.Value()
+
+	go q.run()
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if deliveriesProcessed.Value() != processed+1 {
+		t.Fatalf("Expected deliveries to be counted")
+	}
+
+	if comments := client.Issues.(*githubIssues).comments; len(comments) != 1 {
+		t.Fatalf("Expected one comment, got %v", comments)
+	}
+}
+
+func TestQueueSignature(t *testing.T) {
+	q := &Queue{bot: &Bot{}, jobs: make(chan job, 2)}
+	q.SetSecret("It's a Secret to Everybody")
+
+	// Signatures from https://developer.github.com/webhooks/securing/
+	r := newEventRequest("issues", "Hello, World!")
+	r.Header.Set("X-Hub-Signature-256", "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17")
+
+	if err := q.Enqueue(r); err != nil {
+		t.Fatal(err)
+	}
+
+	r = newEventRequest("issues", "Hello, World!")
+	r.Header.Set("X-Hub-Signature", "sha1=01dc10d0c83e72ed246219cdd91669667fe2ca59")
+
+	if err := q.Enqueue(r); err != nil {
+		t.Fatal(err)
+	}
+
+	r = newEventRequest("issues", "Hello, World!")
+	r.Header.Set("X-Hub-Signature-256", "sha256=0000")
+
+	if err := q.Enqueue(r); err != ErrInvalidSignature {
+		t.Fatalf("Expected invalid signature error, got %v", err)
+	}
+
+	if err := q.Enqueue(newEventRequest("issues", "Hello, World!")); err != ErrInvalidSignature {
+		t.Fatalf("Expected unsigned deliveries to be rejected, got %v", err)
+	}
+}
+
+func TestQueueRedeliver(t *testing.T) {
+	client := newClient(nil)
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+	q := NewQueue(b, 1, nil)
+
+	if _, err := q.Redeliver("72d3162e"); err != ErrDeliveryNotFound {
+		t.Fatalf("Expected delivery not found, got %v", err)
+	}
+
+	if err := b.recordDelivery("72d3162e", "issues", []byte(issueOpenedPayload), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Handled deliveries are handled again when redelivered.
+	delivery, err := q.Redeliver("72d3162e")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(delivery.Error) > 0 || len(delivery.Payload) == 0 {
+		t.Fatalf("Unexpected delivery %v", delivery)
+	}
+
+	if comments := client.Issues.(*githubIssues).comments; len(comments) != 1 {
+		t.Fatalf("Expected delivery to be handled again, got %v", comments)
+	}
+}
+
+func TestQueuePanic(t *testing.T) {
+	client := newClient(nil)
+	reporter := &fakeReporter{}
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore(), reporter: reporter}
+
+	done := make(chan error)
+	q := &Queue{bot: b, jobs: make(chan job, 2), done: func(err error) { done <- err }}
+
+	// Handling panics without an issues client.
+	client.Issues = nil
+
+	r := newEventRequest("issues", issueOpenedPayload)
+	r.Header.Set("X-GitHub-Delivery", "72d3162e")
+
+	if err := q.Enqueue(r); err != nil {
+		t.Fatal(err)
+	}
+
+	go q.run()
+
+	if err := <-done; err == nil || !strings.HasPrefix(err.Error(), "Panic handling issues event") {
+		t.Fatalf("Expected panic error, got %v", err)
+	}
+
+	if len(reporter.errors) != 1 {
+		t.Fatalf("Expected panic to be reported, got %v", reporter.errors)
+	}
+
+	if delivery, err := b.Delivery("72d3162e"); err != nil || !delivery.Failed {
+		t.Fatalf("Expected failed delivery, got %+v, %v", delivery, err)
+	}
+
+	// The queue keeps handling deliveries after a panic.
+	client.Issues = &githubIssues{}
+
+	if err := q.Enqueue(newEventRequest("issues", issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// referenceRegexp matches issue references like #123.
+var referenceRegexp = regexp.MustCompile(`(?:^|[^\w/&])#(\d+)\b`)
+
+// closingRegexp matches closing keywords followed by an issue reference like
+// `Fixes #123`, `closes owner/repo#123` or `resolves https://github.com/owner/repo/issues/123`.
+var closingRegexp = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+(?:([\w.-]+/[\w.-]+)?#(\d+)\b|https?://[^/\s]+/([\w.-]+/[\w.-]+)/issues/(\d+)\b)`)
+
+// issueReferences returns the unique issue numbers referenced in the text.
+func issueReferences(text string) []int {
+	var numbers []int
+
+	for _, match := range referenceRegexp.FindAllStringSubmatch(text, -1) {
+		numbers = appendNumber(numbers, match[1])
+	}
+
+	return numbers
+}
+
+// closingReferences returns the unique issue numbers in the repository that
+// are referenced with a closing keyword in the text.
+func closingReferences(text, repository string) []int {
+	var numbers []int
+
+	for _, match := range closingRegexp.FindAllStringSubmatch(text, -1) {
+		repo, number := match[1], match[2]
+		if len(match[4]) > 0 {
+			repo, number = match[3], match[4]
+		}
+
+		if len(repo) > 0 && strings.ToLower(repo) != strings.ToLower(repository) {
+			continue
+		}
+
+		numbers = appendNumber(numbers, number)
+	}
+
+	return numbers
+}
+
+// appendNumber appends the number if it don't already exists in the slice.
+func appendNumber(numbers []int, s string) []int {
+	number, err := strconv.Atoi(s)
+	if err != nil {
+		return numbers
+	}
+
+	for _, n := range numbers {
+		if n == number {
+			return numbers
+		}
+	}
+
+	return append(numbers, number)
+}
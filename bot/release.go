@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// release comments on the issues referenced in the notes of a published
+// release and on issues fixed by pull requests included in the release.
+func (b *Bot) release(payload *ReleasePayload) error {
+	// Only published releases is handled.
+	if payload.Action != "published" || payload.Release.Draft {
+		return skip("Only published action is handled")
+	}
+
+	if err := b.prepare(payload.payload()); err != nil {
+		return err
+	}
+
+	release := b.config.Release
+	fixes := b.config.Fixes
+
+	if len(strings.TrimSpace(release.Message)) == 0 && !fixes.tracked() {
+		return skip("Release disabled")
+	}
+
+	if payload.Release.Prerelease && !release.Prereleases {
+		return skip("Prereleases is not handled")
+	}
+
+	placeholders := map[string]string{
+		"tag":  payload.Release.TagName,
+		"name": payload.Release.Name,
+		"url":  payload.Release.HTMLURL,
+	}
+
+	if len(strings.TrimSpace(release.Message)) > 0 {
+		message := renderPlaceholders(release.Message, placeholders)
+
+		for _, number := range issueReferences(payload.Release.Body) {
+			if err := b.commentRelease(number, message, release.Labels); err != nil {
+				return err
+			}
+		}
+	}
+
+	if fixes.tracked() {
+		return b.releaseFixes(payload.Release.TagName, placeholders)
+	}
+
+	return nil
+}
+
+// commentRelease comments the release message and adds the release labels.
+func (b *Bot) commentRelease(number int, message string, labels []string) error {
+	unsubscribed, err := b.unsubscribed(number)
+	if err != nil || unsubscribed {
+		return err
+	}
+
+	if len(strings.TrimSpace(message)) > 0 {
+		if err := b.comment(number, message); err != nil {
+			return err
+		}
+	}
+
+	if len(labels) > 0 {
+		_, _, err = b.client.Issues.AddLabelsToIssue(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, labels)
+		if err != nil {
+			return errors.Wrap(err, "github add labels to issue")
+		}
+	}
+
+	return b.record(number, "released")
+}
+
+// comment writes a comment on the issue or pull request.
+func (b *Bot) comment(number int, message string) error {
+	_, _, err := b.client.Issues.CreateComment(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, &github.IssueComment{
+		Body: github.String(message),
+	})
+
+	return errors.Wrap(err, "github create comment")
+}
@@ -0,0 +1,179 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frozzare/hellobot/trace"
+	"github.com/getsentry/raven-go"
+	"github.com/pkg/errors"
+)
+
+// Breadcrumb represents a step in the handling of a webhook delivery.
+type Breadcrumb struct {
+	Time     time.Time
+	Category string
+	Message  string
+}
+
+// ErrorReporter reports errors from handled webhook deliveries.
+type ErrorReporter interface {
+	Report(err error, tags map[string]string, breadcrumbs []Breadcrumb)
+}
+
+// skipError represents an expected skip, like a disabled item or an ignored
+// user, that is not reported.
+type skipError struct {
+	message   string
+	duplicate bool
+}
+
+// Error returns the skip message.
+func (e *skipError) Error() string {
+	return e.message
+}
+
+// skip returns a skip error with the message.
+func skip(message string) error {
+	return &skipError{message: message}
+}
+
+// skipf returns a skip error with the formatted message.
+func skipf(format string, args ...interface{}) error {
+	return &skipError{message: fmt.Sprintf(format, args...)}
+}
+
+// IsSkip returns true when the error is an expected skip and not a failure.
+func IsSkip(err error) bool {
+	_, ok := errors.Cause(err).(*skipError)
+	return ok
+}
+
+// multiError represents several errors from one webhook delivery.
+type multiError []error
+
+// Error returns all error messages separated with semicolons.
+func (m multiError) Error() string {
+	messages := make([]string, len(m))
+	for i, err := range m {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// joinErrors combines the errors from the steps of a webhook delivery, a
+// skip is only returned when all steps were skipped.
+func joinErrors(errs ...error) error {
+	var failures multiError
+	var skipped error
+	succeeded := false
+
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded = true
+		case IsSkip(err):
+			if skipped == nil {
+				skipped = err
+			}
+		default:
+			failures = append(failures, err)
+		}
+	}
+
+	switch {
+	case len(failures) == 1:
+		return failures[0]
+	case len(failures) > 1:
+		return failures
+	case !succeeded:
+		return skipped
+	}
+
+	return nil
+}
+
+// SetReporter sets the reporter errors from handled webhook deliveries are
+// reported to.
+func (b *Bot) SetReporter(reporter ErrorReporter) {
+	b.reporter = reporter
+}
+
+// startSpan starts a span for a step in the handling and adds a breadcrumb
+// for it.
+func (b *Bot) startSpan(name string) (context.Context, *trace.Span) {
+	b.breadcrumbs = append(b.breadcrumbs, Breadcrumb{
+		Time:     b.now(),
+		Category: "step",
+		Message:  name,
+	})
+
+	return trace.Start(b.ctx, name)
+}
+
+// report reports the error from the webhook delivery with tags from the
+// payload, expected skips are not reported.
+func (b *Bot) report(err error, event, delivery string, body []byte) {
+	if err == nil || b.reporter == nil || IsSkip(err) {
+		return
+	}
+
+	tags := map[string]string{
+		"event":    event,
+		"delivery": delivery,
+	}
+
+	// Tags are read from the body since the payload can be from an earlier
+	// delivery when the body can't be decoded.
+	var payload Payload
+	if json.Unmarshal(body, &payload) == nil {
+		tags["action"] = payload.Action
+		tags["repository"] = payload.Repository.Owner.Login + "/" + payload.Repository.Name
+		tags["installation"] = strconv.Itoa(payload.Installation.ID)
+	}
+
+	b.reporter.Report(err, tags, b.breadcrumbs)
+}
+
+// RavenReporter reports errors to Sentry with a raven client, the sample
+// rate and ignored errors are configured on the client.
+type RavenReporter struct {
+	Client *raven.Client
+}
+
+// Report captures the error with the tags and breadcrumbs.
+func (r *RavenReporter) Report(err error, tags map[string]string, breadcrumbs []Breadcrumb) {
+	values := make([]ravenBreadcrumb, len(breadcrumbs))
+
+	for i, b := range breadcrumbs {
+		values[i] = ravenBreadcrumb{
+			Timestamp: b.Time.Unix(),
+			Category:  b.Category,
+			Message:   b.Message,
+		}
+	}
+
+	r.Client.CaptureError(err, tags, &ravenBreadcrumbs{Values: values})
+}
+
+// ravenBreadcrumb represents a Sentry breadcrumb.
+type ravenBreadcrumb struct {
+	Timestamp int64  `json:"timestamp"`
+	Category  string `json:"category"`
+	Message   string `json:"message"`
+}
+
+// ravenBreadcrumbs is the Sentry breadcrumbs interface of an event.
+type ravenBreadcrumbs struct {
+	Values []ravenBreadcrumb `json:"values"`
+}
+
+// Class returns the Sentry interface name.
+func (b *ravenBreadcrumbs) Class() string {
+	return "breadcrumbs"
+}
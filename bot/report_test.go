@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeReporter struct {
+	errors      []error
+	tags        []map[string]string
+	breadcrumbs [][]Breadcrumb
+}
+
+func (r *fakeReporter) Report(err error, tags map[string]string, breadcrumbs []Breadcrumb) {
+	r.errors = append(r.errors, err)
+	r.tags = append(r.tags, tags)
+	r.breadcrumbs = append(r.breadcrumbs, breadcrumbs)
+}
+
+func TestBotReport(t *testing.T) {
+	client := newClient(nil)
+	repositories := &githubRepositories{config: `
+issue:
+  reaction: smile
+`}
+	client.Repositories = repositories
+
+	reporter := &fakeReporter{}
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+	b.SetReporter(reporter)
+
+	r := newEventRequest("issues", issueOpenedPayload)
+	r.Header.Set("X-GitHub-Delivery", "72d3162e")
+
+	if err := b.Handle(r); err == nil {
+		t.Fatal("Expected error for unknown reaction")
+	}
+
+	if len(reporter.errors) != 1 || reporter.errors[0].Error() != "Unknown reaction smile" {
+		t.Fatalf("Expected reported error, got %v", reporter.errors)
+	}
+
+	expected := map[string]string{
+		"event":        "issues",
+		"action":       "opened",
+		"repository":   "test/Fredrik",
+		"installation": "1234",
+		"delivery":     "72d3162e",
+	}
+
+	for k, v := range expected {
+		if reporter.tags[0][k] != v {
+			t.Fatalf("Expected tag %s to be %s, got %v", k, v, reporter.tags[0])
+		}
+	}
+
+	var steps []string
+	for _, crumb := range reporter.breadcrumbs[0] {
+		steps = append(steps, crumb.Message)
+	}
+
+	if len(steps) < 3 || steps[0] != "issues" || steps[1] != "payload.decode" || steps[len(steps)-1] != "config.download" {
+		t.Fatalf("Unexpected breadcrumbs %v", steps)
+	}
+
+	// Expected skips, like disabled items, are not reported.
+	repositories.config = `
+issue:
+  disabled: true
+`
+
+	if err := b.Handle(newEventRequest("issues", issueOpenedPayload)); err == nil || !IsSkip(err) {
+		t.Fatalf("Expected skip error, got %v", err)
+	}
+
+	if len(reporter.errors) != 1 {
+		t.Fatalf("Expected skips not to be reported, got %v", reporter.errors)
+	}
+}
@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// weekdays maps weekday names and abbreviations to weekdays.
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// matches reports whether the schedule is active at the time.
+func (s Schedule) matches(t time.Time) (bool, error) {
+	dates := len(s.Dates) == 0
+
+	for _, r := range s.Dates {
+		from, err := time.ParseInLocation("2006-01-02", r.From, t.Location())
+		if err != nil {
+			return false, errors.Wrapf(err, "schedule %s from date", s.Name)
+		}
+
+		to := from
+		if len(r.To) > 0 {
+			to, err = time.ParseInLocation("2006-01-02", r.To, t.Location())
+			if err != nil {
+				return false, errors.Wrapf(err, "schedule %s to date", s.Name)
+			}
+		}
+
+		if !t.Before(from) && t.Before(to.AddDate(0, 0, 1)) {
+			dates = true
+			break
+		}
+	}
+
+	window := len(s.Weekdays) == 0
+
+	for _, name := range s.Weekdays {
+		weekday, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return false, errors.Errorf("Unknown weekday %s in schedule %s", name, s.Name)
+		}
+
+		if weekday == t.Weekday() {
+			window = true
+			break
+		}
+	}
+
+	if len(s.Hours) > 0 {
+		hours, err := s.withinHours(t)
+		if err != nil {
+			return false, err
+		}
+
+		window = window && hours
+	}
+
+	if s.Outside {
+		window = !window
+	}
+
+	return dates && window, nil
+}
+
+// withinHours reports whether the time is within the hours window.
+// Windows like `22:00-06:00` spans midnight.
+func (s Schedule) withinHours(t time.Time) (bool, error) {
+	parts := strings.Split(s.Hours, "-")
+	if len(parts) != 2 {
+		return false, errors.Errorf("Invalid hours %s in schedule %s", s.Hours, s.Name)
+	}
+
+	from, err := time.Parse("15:04", strings.TrimSpace(parts[0]))
+	if err != nil {
+		return false, errors.Wrapf(err, "schedule %s hours", s.Name)
+	}
+
+	to, err := time.Parse("15:04", strings.TrimSpace(parts[1]))
+	if err != nil {
+		return false, errors.Wrapf(err, "schedule %s hours", s.Name)
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	start := from.Hour()*60 + from.Minute()
+	end := to.Hour()*60 + to.Minute()
+
+	if start <= end {
+		return minute >= start && minute < end, nil
+	}
+
+	return minute >= start || minute < end, nil
+}
+
+// schedule returns the first schedule that is active now, or nil.
+func (b *Bot) schedule() (*Schedule, error) {
+	if b.config == nil {
+		return nil, errors.New("No config exists")
+	}
+
+	for i, s := range b.config.Schedules {
+		timezone := s.Timezone
+		if len(timezone) == 0 {
+			timezone = b.config.Timezone
+		}
+
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, errors.Wrapf(err, "schedule %s timezone", s.Name)
+		}
+
+		ok, err := s.matches(b.now().In(loc))
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return &b.config.Schedules[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// override returns the override of the active schedule for the issue or pull request.
+func (b *Bot) override() (Override, error) {
+	s, err := b.schedule()
+	if err != nil || s == nil {
+		return Override{}, err
+	}
+
+	if b.payload.OnPullRequest() {
+		return s.PullRequest, nil
+	}
+
+	return s.Issue, nil
+}
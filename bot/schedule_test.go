@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScheduleMatches(t *testing.T) {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	vacation := Schedule{Dates: []DateRange{{From: "2018-12-20", To: "2019-01-06"}}}
+	office := Schedule{Weekdays: []string{"mon", "Tuesday", "wed", "thu", "fri"}, Hours: "09:00-17:00", Outside: true}
+	night := Schedule{Hours: "22:00-06:00"}
+
+	tests := []struct {
+		schedule Schedule
+		time     time.Time
+		match    bool
+	}{
+		{vacation, time.Date(2018, 12, 20, 0, 0, 0, 0, stockholm), true},
+		{vacation, time.Date(2019, 1, 6, 23, 59, 0, 0, stockholm), true},
+		{vacation, time.Date(2019, 1, 7, 0, 0, 0, 0, stockholm), false},
+		{vacation, time.Date(2018, 12, 19, 23, 59, 0, 0, stockholm), false},
+		{office, time.Date(2018, 6, 4, 10, 0, 0, 0, stockholm), false},
+		{office, time.Date(2018, 6, 4, 18, 0, 0, 0, stockholm), true},
+		{office, time.Date(2018, 6, 9, 10, 0, 0, 0, stockholm), true},
+		{night, time.Date(2018, 6, 4, 23, 0, 0, 0, stockholm), true},
+		{night, time.Date(2018, 6, 4, 5, 59, 0, 0, stockholm), true},
+		{night, time.Date(2018, 6, 4, 6, 0, 0, 0, stockholm), false},
+	}
+
+	for i, test := range tests {
+		match, err := test.schedule.matches(test.time)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if match != test.match {
+			t.Errorf("Test %d: expected %v, got %v", i, test.match, match)
+		}
+	}
+
+	if _, err := (Schedule{Weekdays: []string{"someday"}}).matches(time.Now()); err == nil {
+		t.Fatal("Expected error for unknown weekday")
+	}
+}
+
+func TestBotScheduleOverride(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+timezone: Europe/Stockholm
+issue:
+  message: Hello @{author}
+  labels:
+    - triage
+schedules:
+  - name: vacation
+    dates:
+      - from: 2018-12-20
+        to: 2019-01-06
+    issue:
+      message: We're away until Jan 6, expect slow responses.
+      labels:
+        - vacation
+`}
+
+	now := time.Date(2018, 12, 24, 12, 0, 0, 0, time.UTC)
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), clock: func() time.Time { return now }}
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := client.Issues.(*githubIssues)
+
+	if expected := []string{"We're away until Jan 6, expect slow responses."}; !reflect.DeepEqual(issues.comments, expected) {
+		t.Fatalf("Expected comments %q, got %q", expected, issues.comments)
+	}
+
+	if expected := []string{"vacation"}; !reflect.DeepEqual(issues.labels, expected) {
+		t.Fatalf("Expected labels %v, got %v", expected, issues.labels)
+	}
+
+	// After the vacation the item message is used.
+	now = time.Date(2019, 1, 7, 12, 0, 0, 0, time.UTC)
+
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	if issues.comments[1] != "Hello test" {
+		t.Fatalf("Expected item message, got %q", issues.comments[1])
+	}
+}
+
+func TestBotScheduleOverrideSkipsItemMessage(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+issue:
+  message_file: .github/missing.md
+schedules:
+  - name: vacation
+    dates:
+      - from: 2018-12-20
+        to: 2019-01-06
+    issue:
+      message: We're away until Jan 6, expect slow responses.
+`}
+
+	now := time.Date(2018, 12, 24, 12, 0, 0, 0, time.UTC)
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), clock: func() time.Time { return now }}
+
+	// The item message file is never loaded when the schedule overrides it.
+	if err := b.SayHello(newRequest(issueOpenedPayload)); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := client.Issues.(*githubIssues)
+
+	if expected := []string{"We're away until Jan 6, expect slow responses."}; !reflect.DeepEqual(issues.comments, expected) {
+		t.Fatalf("Expected comments %q, got %q", expected, issues.comments)
+	}
+}
@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// conventionalRegexp matches Conventional Commits messages like `feat(api)!: add reactions`.
+var conventionalRegexp = regexp.MustCompile(`^(\w+)(?:\(([^()]+)\))?!?: \S.*$`)
+
+// parseConventional validates the message against Conventional Commits with
+// the allowed types and scopes and returns the message type.
+func parseConventional(message string, semantic Semantic) (string, error) {
+	match := conventionalRegexp.FindStringSubmatch(strings.TrimSpace(message))
+	if match == nil {
+		return "", errors.New("does not match `type(scope): description`")
+	}
+
+	typ, scope := strings.ToLower(match[1]), match[2]
+
+	if !containsFold(semantic.types(), typ) {
+		return "", errors.Errorf("unknown type `%s`", typ)
+	}
+
+	if len(scope) > 0 && len(semantic.Scopes) > 0 && !containsFold(semantic.Scopes, scope) {
+		return "", errors.Errorf("unknown scope `%s`", scope)
+	}
+
+	return typ, nil
+}
+
+// containsFold returns true if the list contains the value, ignoring case.
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.ToLower(v) == strings.ToLower(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSemantic creates a check run that tells if the pull request title, and
+// optionally the commits, follows Conventional Commits. The label mapped from
+// the title type is added and the failures are commented.
+func (b *Bot) checkSemantic(number int, sha string) error {
+	if b.client.Checks == nil {
+		return errors.New("No GitHub checks client")
+	}
+
+	semantic := b.config.Semantic
+	title := b.payload.PullRequest.Title
+
+	var failures []string
+
+	typ, err := parseConventional(title, semantic)
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("* Title %q %s", title, err))
+	}
+
+	if semantic.Commits {
+		commits, err := b.listCommits(number)
+		if err != nil {
+			return err
+		}
+
+		for _, commit := range commits {
+			// Merge commits are created by GitHub or git.
+			if len(commit.Parents) > 1 {
+				continue
+			}
+
+			message := firstLine(commit.GetCommit().GetMessage())
+			if _, err := parseConventional(message, semantic); err != nil {
+				failures = append(failures, fmt.Sprintf("* Commit %s %q %s", shortSHA(commit.GetSHA()), message, err))
+			}
+		}
+	}
+
+	now := b.now()
+	run := &CheckRun{
+		Name:        semantic.name(),
+		HeadSHA:     sha,
+		Status:      "completed",
+		Conclusion:  "success",
+		CompletedAt: &now,
+		Output: CheckRunOutput{
+			Title:   "Follows Conventional Commits",
+			Summary: "The pull request follows [Conventional Commits](https://www.conventionalcommits.org/).",
+		},
+	}
+
+	if len(failures) > 0 {
+		run.Conclusion = "failure"
+		run.Output.Title = "Does not follow Conventional Commits"
+		run.Output.Summary = fmt.Sprintf("The pull request must follow [Conventional Commits](https://www.conventionalcommits.org/), allowed types are %s.", strings.Join(semantic.types(), ", "))
+		run.Output.Text = strings.Join(failures, "\n")
+	}
+
+	_, _, err = b.client.Checks.CreateCheckRun(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, run)
+	if err != nil {
+		return errors.Wrap(err, "github create check run")
+	}
+
+	if label, ok := semantic.Labels[typ]; ok && len(typ) > 0 {
+		_, _, err := b.client.Issues.AddLabelsToIssue(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, []string{label})
+		if err != nil {
+			return errors.Wrap(err, "github add labels to issue")
+		}
+	}
+
+	return b.commentSemantic(number, failures)
+}
+
+// commentSemantic comments the failures, the same failures are only commented once.
+func (b *Bot) commentSemantic(number int, failures []string) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	key := "semantic/" + b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name + "/pulls/" + strconv.Itoa(number)
+	text := strings.Join(failures, "\n")
+
+	notified, err := b.getStore().Get(key)
+	if err != nil {
+		return errors.Wrap(err, "get semantic notified")
+	}
+
+	if string(notified) == text {
+		return nil
+	}
+
+	var types []string
+	for _, typ := range b.config.Semantic.types() {
+		types = append(types, "`"+typ+"`")
+	}
+
+	message := renderPlaceholders(b.config.Semantic.message(), map[string]string{
+		"author": b.payload.Sender.Login,
+		"types":  strings.Join(types, ", "),
+		"errors": text,
+	})
+
+	if err := b.comment(number, message); err != nil {
+		return err
+	}
+
+	return errors.Wrap(b.getStore().Set(key, []byte(text)), "set semantic notified")
+}
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestParseConventional(t *testing.T) {
+	semantic := Semantic{Scopes: []string{"api", "ui"}}
+
+	tests := map[string]string{
+		"feat: add reactions":        "",
+		"fix(api)!: remove v1":       "",
+		"Feat(UI): dark mode":        "",
+		"Add reactions":              "does not match `type(scope): description`",
+		"feature: add reactions":     "unknown type `feature`",
+		"fix(cli): handle empty arg": "unknown scope `cli`",
+	}
+
+	for message, expected := range tests {
+		_, err := parseConventional(message, semantic)
+		if (err == nil && len(expected) > 0) || (err != nil && err.Error() != expected) {
+			t.Errorf("parseConventional(%q) = %v, want %q", message, err, expected)
+		}
+	}
+}
+
+func TestBotSemantic(t *testing.T) {
+	client := newClient(nil)
+	client.PullRequests = &githubPullRequests{list: []*github.RepositoryCommit{
+		newCommit("1111111aaa", "octocat", "Octo Cat", "octocat@github.com", "fix: handle empty config"),
+		newCommit("2222222bbb", "octocat", "Octo Cat", "octocat@github.com", "Fix typo"),
+	}}
+	client.Repositories = &githubRepositories{config: `
+pull_request:
+  disabled: true
+semantic:
+  enabled: true
+  commits: true
+  labels:
+    feat: enhancement
+    fix: bug
+  message: "@{errors}"
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	pullRequest := func(action, title string) {
+		err := b.Handle(newEventRequest("pull_request", `
+			{
+				"action": "`+action+`",
+				"pull_request": {
+					"number": 14,
+					"title": "`+title+`",
+					"head": {
+						"sha": "abc123"
+					}
+				},
+				"repository": {
+					"name": "hellobot",
+					"owner": {
+						"login": "frozzare"
+					}
+				},
+				"sender": {
+					"login": "octocat"
+				}
+			}
+		`))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pullRequest("synchronize", "Fix config")
+	pullRequest("synchronize", "Fix config")
+	pullRequest("edited", "fix: handle empty config")
+
+	runs := client.Checks.(*githubChecks).runs
+	if len(runs) != 3 || runs[0].Conclusion != "failure" || runs[2].Conclusion != "failure" {
+		t.Fatalf("Unexpected check runs %v", runs)
+	}
+
+	issues := client.Issues.(*githubIssues)
+
+	expected := []string{
+		"* Title \"Fix config\" does not match `type(scope): description`\n* Commit 2222222 \"Fix typo\" does not match `type(scope): description`",
+		"* Commit 2222222 \"Fix typo\" does not match `type(scope): description`",
+	}
+	if !reflect.DeepEqual(issues.comments, expected) {
+		t.Fatalf("Expected %q, got %q", expected, issues.comments)
+	}
+
+	if !reflect.DeepEqual(issues.labels, []string{"bug"}) {
+		t.Fatalf("Expected bug label, got %v", issues.labels)
+	}
+}
@@ -0,0 +1,176 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// sweepStale marks issues and pull requests without activity as stale and
+// closes stale issues and pull requests without activity after the grace period.
+func (b *Bot) sweepStale() ([]SweepAction, error) {
+	stale := b.config.Stale
+	if stale.DaysUntilStale <= 0 {
+		return nil, nil
+	}
+
+	var actions []SweepAction
+
+	now := b.now()
+	staleBefore := now.AddDate(0, 0, -stale.DaysUntilStale)
+	closeBefore := now.AddDate(0, 0, -stale.DaysUntilClose)
+
+	opts := &github.IssueListByRepoOptions{State: "open", Sort: "updated", Direction: "asc"}
+
+	// Marking and closing issues updates them, which moves them between the
+	// pages, so all issues are listed before any of them are changed.
+	var issues []*github.Issue
+
+	err := b.eachIssue(opts, func(issue *github.Issue) (bool, error) {
+		updated := issue.GetUpdatedAt()
+
+		// Issues are sorted by last update so the rest has recent activity.
+		if !updated.Before(staleBefore) && (stale.DaysUntilClose <= 0 || !updated.Before(closeBefore)) {
+			return false, nil
+		}
+
+		if !hasLabel(issue, stale.ExemptLabels...) {
+			issues = append(issues, issue)
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		number := issue.GetNumber()
+		author := issue.GetUser().GetLogin()
+		updated := issue.GetUpdatedAt()
+
+		if hasLabel(issue, stale.label()) {
+			if stale.DaysUntilClose <= 0 || !updated.Before(closeBefore) {
+				continue
+			}
+
+			actions = append(actions, b.action(number, "close", stale.DryRun))
+
+			if stale.DryRun {
+				continue
+			}
+
+			if err := b.closeStale(number, renderMessage(stale.CloseMessage, author)); err != nil {
+				return actions, err
+			}
+
+			continue
+		}
+
+		if !updated.Before(staleBefore) {
+			continue
+		}
+
+		actions = append(actions, b.action(number, "stale", stale.DryRun))
+
+		if stale.DryRun {
+			continue
+		}
+
+		if err := b.markStale(number, stale.label(), renderMessage(stale.Message, author)); err != nil {
+			return actions, err
+		}
+	}
+
+	return actions, nil
+}
+
+// markStale comments the warning message and adds the stale label.
+func (b *Bot) markStale(number int, label, message string) error {
+	if len(strings.TrimSpace(message)) > 0 {
+		_, _, err := b.client.Issues.CreateComment(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, &github.IssueComment{
+			Body: github.String(message),
+		})
+		if err != nil {
+			return errors.Wrap(err, "github create comment")
+		}
+	}
+
+	_, _, err := b.client.Issues.AddLabelsToIssue(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, []string{label})
+	if err != nil {
+		return errors.Wrap(err, "github add labels to issue")
+	}
+
+	return b.record(number, "stale")
+}
+
+// closeStale comments the close message and closes the issue or pull request.
+func (b *Bot) closeStale(number int, message string) error {
+	if len(strings.TrimSpace(message)) > 0 {
+		_, _, err := b.client.Issues.CreateComment(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, &github.IssueComment{
+			Body: github.String(message),
+		})
+		if err != nil {
+			return errors.Wrap(err, "github create comment")
+		}
+	}
+
+	_, _, err := b.client.Issues.Edit(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, &github.IssueRequest{
+		State: github.String("closed"),
+	})
+	if err != nil {
+		return errors.Wrap(err, "github close issue")
+	}
+
+	return b.record(number, "closed")
+}
+
+// handleActivity removes the stale label when there is new activity
+// on a stale issue or pull request.
+func (b *Bot) handleActivity(payload *Payload) error {
+	// Activity from bots, like the stale warning itself, is not new activity.
+	if payload.Sender.Type == "Bot" {
+		return skip("Activity from bots is not handled")
+	}
+
+	if err := b.prepare(payload); err != nil {
+		return err
+	}
+
+	if b.config == nil || b.config.Stale.DaysUntilStale <= 0 {
+		return skip("Stale is disabled")
+	}
+
+	label := b.config.Stale.label()
+
+	// Adding or removing the stale label, also by hand, is not new activity.
+	if (payload.Action == "labeled" || payload.Action == "unlabeled") && strings.ToLower(payload.Label.Name) == strings.ToLower(label) {
+		return skip("Stale label events are not activity")
+	}
+
+	stale := false
+
+	for _, l := range b.payload.Labels() {
+		if strings.ToLower(l.Name) == strings.ToLower(label) {
+			stale = true
+		}
+	}
+
+	if !stale {
+		return nil
+	}
+
+	number, err := b.number()
+	if err != nil {
+		return err
+	}
+
+	if b.config.Stale.DryRun {
+		return nil
+	}
+
+	_, err = b.client.Issues.RemoveLabelForIssue(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, number, label)
+
+	return errors.Wrap(err, "github remove label for issue")
+}
@@ -0,0 +1,222 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const staleConfig = `
+stale:
+  days_until_stale: 30
+  days_until_close: 7
+  exempt_labels:
+    - pinned
+  message: This issue has been marked as stale @{author}.
+  close_message: Closing stale issue.
+`
+
+func newStaleIssue(number int, updated time.Time, labels ...string) *github.Issue {
+	issue := &github.Issue{
+		Number:    github.Int(number),
+		State:     github.String("open"),
+		UpdatedAt: &updated,
+		User:      &github.User{Login: github.String("octocat")},
+	}
+
+	for _, label := range labels {
+		issue.Labels = append(issue.Labels, github.Label{Name: github.String(label)})
+	}
+
+	return issue
+}
+
+func newSweepBot(config string, now time.Time, issues ...*github.Issue) (*Bot, *githubIssues) {
+	client := newClient(nil)
+	client.Apps = &githubApps{
+		installations: []*github.Installation{{ID: github.Int64(1)}},
+		repos: []*github.Repository{
+			{Name: github.String("hellobot"), FullName: github.String("frozzare/hellobot"), Owner: &github.User{Login: github.String("frozzare")}},
+			{Name: github.String("secret"), Private: github.Bool(true), Owner: &github.User{Login: github.String("frozzare")}},
+		},
+	}
+	client.Repositories = &githubRepositories{config: config}
+	issuesService := &githubIssues{issues: issues}
+	client.Issues = issuesService
+
+	b := &Bot{
+		id:        1234,
+		ctx:       context.Background(),
+		appClient: client,
+		clients:   map[int]*githubClient{1: client},
+		clock:     func() time.Time { return now },
+	}
+
+	return b, issuesService
+}
+
+func TestBotSweepStale(t *testing.T) {
+	now := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	b, issues := newSweepBot(staleConfig, now,
+		newStaleIssue(1, now.AddDate(0, 0, -60)),
+		newStaleIssue(2, now.AddDate(0, 0, -60), "pinned"),
+		newStaleIssue(3, now.AddDate(0, 0, -10), "stale"),
+		newStaleIssue(4, now.AddDate(0, 0, -3), "stale"),
+		newStaleIssue(5, now.AddDate(0, 0, -1)),
+	)
+
+	actions, err := b.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []SweepAction{
+		{Repository: "frozzare/hellobot", Number: 1, Action: "stale"},
+		{Repository: "frozzare/hellobot", Number: 3, Action: "close"},
+	}
+
+	if !reflect.DeepEqual(actions, expected) {
+		t.Fatalf("Expected actions %v, got %v", expected, actions)
+	}
+
+	if expected := []string{"This issue has been marked as stale octocat.", "Closing stale issue."}; !reflect.DeepEqual(issues.comments, expected) {
+		t.Fatalf("Expected comments %q, got %q", expected, issues.comments)
+	}
+
+	if expected := []string{"stale"}; !reflect.DeepEqual(issues.labels, expected) {
+		t.Fatalf("Expected labels %v, got %v", expected, issues.labels)
+	}
+
+	if len(issues.edits) != 1 || issues.edits[0].GetState() != "closed" {
+		t.Fatalf("Expected issue to be closed, got %v", issues.edits)
+	}
+}
+
+func TestBotSweepStalePages(t *testing.T) {
+	now := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	b, issues := newSweepBot(staleConfig, now,
+		newStaleIssue(1, now.AddDate(0, 0, -60)),
+		newStaleIssue(2, now.AddDate(0, 0, -60)),
+		newStaleIssue(3, now.AddDate(0, 0, -60)),
+	)
+
+	// Marked issues are moved last, which would skip issues on later pages.
+	issues.perPage = 1
+
+	actions, err := b.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []SweepAction{
+		{Repository: "frozzare/hellobot", Number: 1, Action: "stale"},
+		{Repository: "frozzare/hellobot", Number: 2, Action: "stale"},
+		{Repository: "frozzare/hellobot", Number: 3, Action: "stale"},
+	}
+
+	if !reflect.DeepEqual(actions, expected) || len(issues.labels) != 3 {
+		t.Fatalf("Expected actions %v, got %v", expected, actions)
+	}
+}
+
+func TestBotSweepStaleDryRun(t *testing.T) {
+	now := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	b, issues := newSweepBot(staleConfig+"  dry_run: true\n", now,
+		newStaleIssue(1, now.AddDate(0, 0, -60)),
+	)
+
+	actions, err := b.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actions) != 1 || !actions[0].DryRun {
+		t.Fatalf("Expected one dry run action, got %v", actions)
+	}
+
+	if len(issues.comments) != 0 || len(issues.labels) != 0 {
+		t.Fatal("Dry run should not comment or label")
+	}
+}
+
+func TestBotStaleActivity(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: staleConfig}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	r := newRequest(`
+		{
+			"action": "created",
+			"issue": {
+				"number": 1234,
+				"labels": [{"name": "stale"}]
+			},
+			"repository": {
+				"name": "Fredrik",
+				"owner": {
+					"login": "test"
+				}
+			},
+			"sender": {
+				"login": "octocat",
+				"type": "User"
+			}
+		}
+	`)
+	r.Header = http.Header{}
+	r.Header.Set("X-GitHub-Event", "issue_comment")
+
+	if err := b.Handle(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if removed := client.Issues.(*githubIssues).removed; !reflect.DeepEqual(removed, []string{"stale"}) {
+		t.Fatalf("Expected stale label to be removed, got %v", removed)
+	}
+}
+
+func TestBotStaleLabeled(t *testing.T) {
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: staleConfig}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client)}
+
+	r := newRequest(`
+		{
+			"action": "labeled",
+			"label": {"name": "stale"},
+			"issue": {
+				"number": 1234,
+				"labels": [{"name": "stale"}]
+			},
+			"repository": {
+				"name": "Fredrik",
+				"owner": {
+					"login": "test"
+				}
+			},
+			"sender": {
+				"login": "octocat",
+				"type": "User"
+			}
+		}
+	`)
+	r.Header = http.Header{}
+	r.Header.Set("X-GitHub-Event", "issues")
+
+	if err := b.Handle(r); !IsSkip(err) {
+		t.Fatalf("Expected stale label event to be skipped, got %v", err)
+	}
+
+	if removed := client.Issues.(*githubIssues).removed; len(removed) > 0 {
+		t.Fatalf("Expected stale label to be kept, got %v", removed)
+	}
+}
@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// greetingsPeriod is the period recent greetings are counted for.
+const greetingsPeriod = 30 * 24 * time.Hour
+
+// RepositoryStatus represents the public status of the bot in a repository.
+type RepositoryStatus struct {
+	Repository         string
+	Installed          bool
+	ConfigFound        bool
+	ConfigErrors       []string
+	IssueMessage       string
+	PullRequestMessage string
+	Greetings          int
+}
+
+// ConfigValid returns true if the config was found and has no problems.
+func (s *RepositoryStatus) ConfigValid() bool {
+	return s.ConfigFound && len(s.ConfigErrors) == 0
+}
+
+// installedRepository represents a repository the app is installed in.
+type installedRepository struct {
+	Installation int    `json:"installation"`
+	Owner        string `json:"owner"`
+	Name         string `json:"name"`
+	Private      bool   `json:"private"`
+}
+
+// RepositoryStatus returns the status of the bot in the public repository, like
+// if the app is installed, if the config is valid and the greeting messages.
+func (b *Bot) RepositoryStatus(owner, name string) (*RepositoryStatus, error) {
+	status := &RepositoryStatus{Repository: owner + "/" + name}
+	ctx := context.Background()
+
+	repos, err := b.installedRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Private repositories are not handled and must not be shown.
+	repo, ok := repos[strings.ToLower(owner+"/"+name)]
+	if !ok || repo.Private {
+		return status, nil
+	}
+
+	status.Installed = true
+
+	client, err := b.installationClient(repo.Installation)
+	if err != nil {
+		return nil, err
+	}
+
+	rb := b.repositoryBot(ctx, client, repo.Installation, repo.Owner, repo.Name)
+
+	return status, rb.repositoryStatus(status)
+}
+
+// installedRepositories returns the repositories in all installations by the
+// lowercase full name. The list is cached with the downloaded files, so the
+// installations are not listed again for each repository status.
+func (b *Bot) installedRepositories(ctx context.Context) (map[string]installedRepository, error) {
+	const key = "installed-repositories"
+
+	repos := map[string]installedRepository{}
+
+	if b.cache != nil {
+		if data, ok := b.cache.get(key, b.now()); ok {
+			return repos, errors.Wrap(json.Unmarshal(data, &repos), "unmarshal installed repositories")
+		}
+	}
+
+	app, err := b.createAppClient()
+	if err != nil {
+		return nil, err
+	}
+
+	installations, err := b.listInstallations(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, installation := range installations {
+		id := int(installation.GetID())
+
+		client, err := b.installationClient(id)
+		if err != nil {
+			return nil, err
+		}
+
+		list, err := b.listRepos(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, repo := range list {
+			owner := repo.GetOwner().GetLogin()
+
+			repos[strings.ToLower(owner+"/"+repo.GetName())] = installedRepository{
+				Installation: id,
+				Owner:        owner,
+				Name:         repo.GetName(),
+				Private:      repo.GetPrivate(),
+			}
+		}
+	}
+
+	if b.cache != nil {
+		data, err := json.Marshal(repos)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal installed repositories")
+		}
+
+		b.cache.set(key, data, b.now())
+	}
+
+	return repos, nil
+}
+
+// repositoryStatus adds the config, messages and greetings to the status.
+func (b *Bot) repositoryStatus(status *RepositoryStatus) error {
+	// A missing config file is not a config error.
+	data, err := b.downloadFile(".hello.yml")
+	if err != nil {
+		return nil
+	}
+
+	status.ConfigFound = true
+
+	config, err := parseConfig(data)
+	if err != nil {
+		status.ConfigErrors = []string{err.Error()}
+		return nil
+	}
+
+	if config == nil {
+		config = &Config{}
+	}
+
+	b.config = config
+	status.ConfigErrors = config.problems()
+
+	if status.IssueMessage, err = b.previewMessage(config.Issue); err != nil {
+		status.ConfigErrors = append(status.ConfigErrors, "issue: "+err.Error())
+	}
+
+	if status.PullRequestMessage, err = b.previewMessage(config.PullRequest); err != nil {
+		status.ConfigErrors = append(status.ConfigErrors, "pull_request: "+err.Error())
+	}
+
+	activities, err := b.Activity(b.repository(), 0)
+	if err != nil {
+		return err
+	}
+
+	since := b.now().Add(-greetingsPeriod)
+
+	for _, activity := range activities {
+		if activity.Action == "greeted" && activity.Time.After(since) {
+			status.Greetings++
+		}
+	}
+
+	return nil
+}
+
+// previewMessage returns the item message, or the first message variant,
+// without selecting variants so rotation positions are not changed.
+func (b *Bot) previewMessage(item Item) (string, error) {
+	if item.Disabled {
+		return "", nil
+	}
+
+	message, messageFile := item.Message, item.MessageFile
+	if len(item.Messages) > 0 {
+		message, messageFile = item.Messages[0].Message, item.Messages[0].MessageFile
+	}
+
+	return b.loadMessage(message, messageFile)
+}
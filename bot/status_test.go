@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestBotRepositoryStatus(t *testing.T) {
+	now := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	client := newClient(nil)
+	client.Apps = &githubApps{
+		installations: []*github.Installation{{ID: github.Int64(1), Account: &github.User{Login: github.String("frozzare")}}},
+		repos: []*github.Repository{
+			{Name: github.String("hellobot"), Owner: &github.User{Login: github.String("frozzare")}},
+			{Name: github.String("secret"), Private: github.Bool(true), Owner: &github.User{Login: github.String("frozzare")}},
+		},
+	}
+	client.Repositories = &githubRepositories{config: `
+issue:
+  messages:
+    - Hello @{author}!
+    - Hi @{author}!
+  selection: rotate
+pull_request:
+  disabled: true
+`}
+
+	store := NewMemoryStore()
+	b := &Bot{id: 1234, ctx: context.Background(), appClient: client, clients: map[int]*githubClient{1: client}, cache: newFileCache(time.Minute), store: store, clock: func() time.Time { return now }}
+
+	rb := b.repositoryBot(context.Background(), client, 1, "frozzare", "hellobot")
+	rb.record(1, "greeted")
+	rb.record(2, "stale")
+
+	status, err := b.RepositoryStatus("frozzare", "hellobot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.Installed || !status.ConfigValid() || status.IssueMessage != "Hello @{author}!" || len(status.PullRequestMessage) > 0 || status.Greetings != 1 {
+		t.Fatalf("Unexpected status %+v", status)
+	}
+
+	// Previews don't change the rotation position.
+	if keys, _ := store.Keys("messages/"); len(keys) != 0 {
+		t.Fatalf("Expected no rotation positions, got %v", keys)
+	}
+
+	for _, repo := range []string{"secret", "other"} {
+		status, err := b.RepositoryStatus("frozzare", repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status.Installed {
+			t.Fatalf("Expected %s to not be installed", repo)
+		}
+	}
+
+	// The installations are only listed once for all repositories.
+	if listed := client.Apps.(*githubApps).listed; listed != 1 {
+		t.Fatalf("Expected installations to be listed once, got %d", listed)
+	}
+}
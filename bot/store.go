@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Store persists bot state, like round robin positions and activity, between webhooks.
+type Store interface {
+	// Get returns the value for the key or nil if the key don't exists.
+	Get(key string) ([]byte, error)
+
+	// Set sets the value for the key.
+	Set(key string, value []byte) error
+
+	// Delete deletes the key.
+	Delete(key string) error
+
+	// Keys returns the sorted keys that starts with the prefix.
+	Keys(prefix string) ([]string, error)
+}
+
+// MemoryStore represents a store that only keeps state in memory.
+type MemoryStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+// NewMemoryStore creates a new memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: map[string][]byte{}}
+}
+
+// Get returns the value for the key or nil if the key don't exists.
+func (s *MemoryStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data[key], nil
+}
+
+// Set sets the value for the key.
+func (s *MemoryStore) Set(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[key] = value
+
+	return nil
+}
+
+// Delete deletes the key.
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, key)
+
+	return nil
+}
+
+// Keys returns the sorted keys that starts with the prefix.
+func (s *MemoryStore) Keys(prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return keys(s.data, prefix), nil
+}
+
+// BoltStore represents a store that keeps state in a BoltDB file. Keys are
+// kept in a bucket for the first part of the key, like `activity`, so keys
+// with a prefix are found without reading the other buckets.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens the BoltDB file and creates it if it don't exists.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "opening store file")
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// bucket returns the bucket name for the key.
+func bucket(key string) []byte {
+	if i := strings.Index(key, "/"); i > 0 {
+		return []byte(key[:i])
+	}
+
+	return []byte("default")
+}
+
+// Get returns the value for the key or nil if the key don't exists.
+func (s *BoltStore) Get(key string) ([]byte, error) {
+	var value []byte
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(bucket(key)); b != nil {
+			// Values are only valid during the transaction.
+			if v := b.Get([]byte(key)); v != nil {
+				value = append([]byte{}, v...)
+			}
+		}
+
+		return nil
+	})
+
+	return value, errors.Wrap(err, "get store value")
+}
+
+// Set sets the value for the key.
+func (s *BoltStore) Set(key string, value []byte) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucket(key))
+		if err != nil {
+			return err
+		}
+
+		return b.Put([]byte(key), value)
+	})
+
+	return errors.Wrap(err, "set store value")
+}
+
+// Delete deletes the key.
+func (s *BoltStore) Delete(key string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(bucket(key)); b != nil {
+			return b.Delete([]byte(key))
+		}
+
+		return nil
+	})
+
+	return errors.Wrap(err, "delete store value")
+}
+
+// Keys returns the sorted keys that starts with the prefix.
+func (s *BoltStore) Keys(prefix string) ([]string, error) {
+	var list []string
+
+	seek := func(b *bolt.Bucket) {
+		c := b.Cursor()
+		for k, _ := c.Seek([]byte(prefix)); k != nil && strings.HasPrefix(string(k), prefix); k, _ = c.Next() {
+			list = append(list, string(k))
+		}
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// Prefixes with a bucket only reads that bucket.
+		if strings.Contains(prefix, "/") {
+			if b := tx.Bucket(bucket(prefix)); b != nil {
+				seek(b)
+			}
+
+			return nil
+		}
+
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			seek(b)
+			return nil
+		})
+	})
+
+	sort.Strings(list)
+
+	return list, errors.Wrap(err, "list store keys")
+}
+
+// Close closes the BoltDB file.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// keys returns the sorted keys in the data that starts with the prefix.
+func keys(data map[string][]byte, prefix string) []string {
+	var list []string
+
+	for key := range data {
+		if strings.HasPrefix(key, prefix) {
+			list = append(list, key)
+		}
+	}
+
+	sort.Strings(list)
+
+	return list
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBoltStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hellobot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "store.db")
+
+	s, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, _ := s.Get("foo"); v != nil {
+		t.Fatalf("Expected nil, got %s", v)
+	}
+
+	for _, key := range []string{"foo", "b/2", "a/1", "b/1", "bar/1"} {
+		if err := s.Set(key, []byte("bar")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Delete("b/2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if keys, _ := s.Keys("b/"); !reflect.DeepEqual(keys, []string{"b/1"}) {
+		t.Fatalf("Expected [b/1], got %v", keys)
+	}
+
+	if keys, _ := s.Keys("b"); !reflect.DeepEqual(keys, []string{"b/1", "bar/1"}) {
+		t.Fatalf("Expected [b/1 bar/1], got %v", keys)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if v, _ := s.Get("foo"); string(v) != "bar" {
+		t.Fatalf("Expected bar, got %s", v)
+	}
+}
+
+func TestMemoryStoreKeys(t *testing.T) {
+	s := NewMemoryStore()
+
+	for _, key := range []string{"b/2", "a/1", "b/1"} {
+		if err := s.Set(key, []byte("1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Delete("b/2"); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ := s.Keys("b/")
+	if len(keys) != 1 || keys[0] != "b/1" {
+		t.Fatalf("Expected [b/1], got %v", keys)
+	}
+}
@@ -0,0 +1,251 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// SweepAction represents an action taken by a scheduled sweep.
+type SweepAction struct {
+	Repository string
+	Number     int
+	Action     string
+	DryRun     bool
+}
+
+// String returns a string representation of the action.
+func (a SweepAction) String() string {
+	s := fmt.Sprintf("%s#%d: %s", a.Repository, a.Number, a.Action)
+
+	if a.DryRun {
+		s += " (dry run)"
+	}
+
+	return s
+}
+
+// Sweep runs the scheduled passes, like marking stale issues and pull requests,
+// for all public repositories in all installations of the app.
+func (b *Bot) Sweep() ([]SweepAction, error) {
+	// Sweeps runs beside the webhook queue, so the context of the webhook
+	// being handled is never used.
+	ctx := context.Background()
+
+	app, err := b.createAppClient()
+	if err != nil {
+		return nil, err
+	}
+
+	installations, err := b.listInstallations(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []SweepAction
+	var failed []string
+
+	// The lock limit applies to the whole sweep to stay within rate limits.
+	locked := 0
+
+	if err := b.pruneDeliveries(); err != nil {
+		failed = append(failed, err.Error())
+	}
+
+	if err := b.pruneActivity(); err != nil {
+		failed = append(failed, err.Error())
+	}
+
+	for _, installation := range installations {
+		id := int(installation.GetID())
+
+		client, err := b.installationClient(id)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("installation %d: %s", id, err))
+			continue
+		}
+
+		repos, err := b.listRepos(ctx, client)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("installation %d: %s", id, err))
+			continue
+		}
+
+		for _, repo := range repos {
+			if repo.GetPrivate() {
+				continue
+			}
+
+			rb := b.repositoryBot(ctx, client, id, repo.GetOwner().GetLogin(), repo.GetName())
+
+			// Repositories without a config file are skipped.
+			config, err := rb.downloadConfig()
+			if err != nil || config == nil {
+				continue
+			}
+
+			rb.config = config
+
+			a, err := rb.sweepRepository(locked)
+			actions = append(actions, a...)
+
+			for _, action := range a {
+				if action.Action == "lock" {
+					locked++
+				}
+			}
+
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %s", repo.GetFullName(), err))
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return actions, errors.Errorf("Sweep failed: %s", strings.Join(failed, ", "))
+	}
+
+	return actions, nil
+}
+
+// repositoryBot returns a bot for a repository that shares clients, store and clock.
+func (b *Bot) repositoryBot(ctx context.Context, client *githubClient, installation int, owner, name string) *Bot {
+	payload := &Payload{}
+	payload.Repository.Owner.Login = owner
+	payload.Repository.Name = name
+	payload.Installation.ID = installation
+
+	return &Bot{
+		id:        b.id,
+		key:       b.key,
+		baseURL:   b.baseURL,
+		uploadURL: b.uploadURL,
+		client:    client,
+		payload:   payload,
+		cache:     b.cache,
+		store:     b.getStore(),
+		clock:     b.clock,
+		ctx:       ctx,
+	}
+}
+
+// sweepRepository runs the scheduled passes for the repository, locked is how
+// many issues and pull requests the sweep has locked in other repositories.
+func (b *Bot) sweepRepository(locked int) ([]SweepAction, error) {
+	actions, err := b.sweepStale()
+	if err != nil {
+		return actions, err
+	}
+
+	a, err := b.sweepLock(locked)
+
+	return append(actions, a...), err
+}
+
+// action returns a sweep action for the issue or pull request in the repository.
+func (b *Bot) action(number int, action string, dryRun bool) SweepAction {
+	return SweepAction{
+		Repository: b.payload.Repository.Owner.Login + "/" + b.payload.Repository.Name,
+		Number:     number,
+		Action:     action,
+		DryRun:     dryRun,
+	}
+}
+
+// eachIssue calls fn for each subscribed issue and pull request in the
+// repository until fn returns false or there are no more pages.
+func (b *Bot) eachIssue(opts *github.IssueListByRepoOptions, fn func(*github.Issue) (bool, error)) error {
+	opts.PerPage = 100
+
+	for {
+		issues, res, err := b.client.Issues.ListByRepo(b.ctx, b.payload.Repository.Owner.Login, b.payload.Repository.Name, opts)
+		if err != nil {
+			return errors.Wrap(err, "github list issues by repo")
+		}
+
+		for _, issue := range issues {
+			// Issues the bot has been unsubscribed from are skipped.
+			unsubscribed, err := b.unsubscribed(issue.GetNumber())
+			if err != nil {
+				return err
+			}
+
+			if unsubscribed {
+				continue
+			}
+
+			next, err := fn(issue)
+			if err != nil {
+				return err
+			}
+
+			if !next {
+				return nil
+			}
+		}
+
+		if res == nil || res.NextPage == 0 {
+			return nil
+		}
+
+		opts.Page = res.NextPage
+	}
+}
+
+// hasLabel returns true if the issue has any of the labels.
+func hasLabel(issue *github.Issue, names ...string) bool {
+	for _, label := range issue.Labels {
+		for _, name := range names {
+			if strings.ToLower(label.GetName()) == strings.ToLower(name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// listInstallations returns all installations of the app.
+func (b *Bot) listInstallations(ctx context.Context, client *githubClient) ([]*github.Installation, error) {
+	var installations []*github.Installation
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		page, res, err := client.Apps.ListInstallations(ctx, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "github list installations")
+		}
+
+		installations = append(installations, page...)
+
+		if res == nil || res.NextPage == 0 {
+			return installations, nil
+		}
+
+		opts.Page = res.NextPage
+	}
+}
+
+// listRepos returns all repositories the installation has access to.
+func (b *Bot) listRepos(ctx context.Context, client *githubClient) ([]*github.Repository, error) {
+	var repos []*github.Repository
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		page, res, err := client.Apps.ListRepos(ctx, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "github list repos")
+		}
+
+		repos = append(repos, page...)
+
+		if res == nil || res.NextPage == 0 {
+			return repos, nil
+		}
+
+		opts.Page = res.NextPage
+	}
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/frozzare/hellobot/trace"
+)
+
+func TestBotTrace(t *testing.T) {
+	exporter := &trace.InMemoryExporter{}
+	trace.SetExporter(exporter)
+	defer trace.SetExporter(trace.NoopExporter{})
+
+	client := newClient(nil)
+	client.Repositories = &githubRepositories{config: `
+pull_request:
+  message: Hello @{author}
+  assignees:
+    users:
+      - octocat
+`}
+
+	b := &Bot{id: 1234, ctx: context.Background(), clients: installations(client), store: NewMemoryStore()}
+
+	done := make(chan error)
+	q := &Queue{bot: b, jobs: make(chan job, 1), done: func(err error) { done <- err }}
+
+	r := newEventRequest("pull_request", pullRequestOpenedPayload)
+	r.Header.Set("X-GitHub-Delivery", "abc-123")
+
+	if err := q.Enqueue(r); err != nil {
+		t.Fatal(err)
+	}
+
+	go q.run()
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	spans := map[string]*trace.Span{}
+	for _, span := range exporter.Spans() {
+		spans[span.Name] = span
+	}
+
+	for _, name := range []string{"webhook.receive", "webhook.handle", "payload.decode", "config.download", "rule.schedule", "rule.assignees"} {
+		if _, ok := spans[name]; !ok {
+			t.Fatalf("Expected %s span, got %v", name, exporter.Spans())
+		}
+	}
+
+	receive := spans["webhook.receive"]
+	handle := spans["webhook.handle"]
+
+	if handle.TraceID != receive.TraceID || handle.ParentID != receive.SpanID {
+		t.Fatal("Expected webhook handling to be a child of the received request")
+	}
+
+	if spans["rule.assignees"].TraceID != receive.TraceID {
+		t.Fatal("Expected rule spans in the same trace")
+	}
+
+	for _, name := range []string{"webhook.handle", "config.download", "rule.assignees"} {
+		span := spans[name]
+
+		if span.Attributes["github.delivery"] != "abc-123" {
+			t.Fatalf("Expected delivery attribute on %s, got %v", name, span.Attributes)
+		}
+
+		if span.Attributes["github.repository"] != "test/Fredrik" {
+			t.Fatalf("Expected repository attribute on %s, got %v", name, span.Attributes)
+		}
+	}
+
+	if b.ctx != context.Background() {
+		t.Fatal("Expected bot context to be restored")
+	}
+}
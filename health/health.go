@@ -0,0 +1,73 @@
+// Package health implements the health, readiness and version endpoints.
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"sort"
+)
+
+// Check represents a readiness check that returns an error when not ready.
+type Check func() error
+
+// Build represents the build information.
+type Build struct {
+	Commit string `json:"commit"`
+	Time   string `json:"time"`
+	Go     string `json:"go"`
+}
+
+// Healthz tells that the process is up.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz returns a handler that runs the checks and tells if the process
+// is ready to handle webhooks.
+func Readyz(checks map[string]Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusOK
+		results := map[string]string{}
+
+		names := make([]string, 0, len(checks))
+		for name := range checks {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			if err := checks[name](); err != nil {
+				status = http.StatusServiceUnavailable
+				results[name] = err.Error()
+			} else {
+				results[name] = "ok"
+			}
+		}
+
+		res := map[string]interface{}{"status": "ok", "checks": results}
+		if status != http.StatusOK {
+			res["status"] = "unavailable"
+		}
+
+		writeJSON(w, status, res)
+	}
+}
+
+// Version returns a handler that writes the build information.
+func Version(commit, time string) http.HandlerFunc {
+	build := Build{Commit: commit, Time: time, Go: runtime.Version()}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, build)
+	}
+}
+
+// writeJSON writes the value as JSON with the status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
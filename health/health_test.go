@@ -0,0 +1,62 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadyz(t *testing.T) {
+	ready := false
+
+	h := Readyz(map[string]Check{
+		"key": func() error { return nil },
+		"queue": func() error {
+			if !ready {
+				return errors.New("Queue is saturated")
+			}
+
+			return nil
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/readyz", nil))
+
+	var res struct {
+		Status string
+		Checks map[string]string
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Code != 503 || res.Status != "unavailable" || res.Checks["queue"] != "Queue is saturated" || res.Checks["key"] != "ok" {
+		t.Fatalf("Unexpected response %d %+v", w.Code, res)
+	}
+
+	ready = true
+
+	w = httptest.NewRecorder()
+	h(w, httptest.NewRequest("GET", "/readyz", nil))
+
+	if w.Code != 200 {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	w := httptest.NewRecorder()
+	Version("abc123", "2018-05-01T12:00:00Z")(w, httptest.NewRequest("GET", "/version", nil))
+
+	var build Build
+	if err := json.NewDecoder(w.Body).Decode(&build); err != nil {
+		t.Fatal(err)
+	}
+
+	if build.Commit != "abc123" || build.Time != "2018-05-01T12:00:00Z" || len(build.Go) == 0 {
+		t.Fatalf("Unexpected build %+v", build)
+	}
+}
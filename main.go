@@ -1,14 +1,21 @@
 package main
 
 import (
-	_ "expvar"
-	"log"
+	"errors"
+	"expvar"
+	"fmt"
 	"net/http"
 	"os"
-	"strconv"
+	"time"
 
 	"github.com/TV4/graceful"
+	"github.com/frozzare/hellobot/admin"
 	"github.com/frozzare/hellobot/bot"
+	"github.com/frozzare/hellobot/health"
+	"github.com/frozzare/hellobot/playground"
+	"github.com/frozzare/hellobot/server"
+	"github.com/frozzare/hellobot/status"
+	"github.com/frozzare/hellobot/trace"
 	"github.com/getsentry/raven-go"
 	"github.com/stathat/go"
 )
@@ -16,14 +23,25 @@ import (
 var (
 	stathatEmail string
 	bt           *bot.Bot
-	logger       *log.Logger
+	queue        *bot.Queue
+	logger       *server.Logger
+
+	// commit and buildTime are set at build time with
+	// -ldflags "-X main.commit=... -X main.buildTime=...".
+	commit    = "unknown"
+	buildTime = "unknown"
 )
 
-func init() {
-	dsn := os.Getenv("RAVEN_DSN")
-	if len(dsn) > 0 {
-		raven.SetDSN(dsn)
-	}
+// adminBot redelivers webhooks with the queue, so redeliveries are never
+// handled beside the queued deliveries.
+type adminBot struct {
+	*bot.Bot
+	queue *bot.Queue
+}
+
+// Redeliver handles the recorded webhook delivery again with the queue.
+func (b *adminBot) Redeliver(id string) (*bot.Delivery, error) {
+	return b.queue.Redeliver(id)
 }
 
 func helloHandler(w http.ResponseWriter, r *http.Request) {
@@ -32,10 +50,18 @@ func helloHandler(w http.ResponseWriter, r *http.Request) {
 			stathat.PostEZCount("hello.requests", stathatEmail, 1)
 		}
 
-		if err := bt.SayHello(r); err != nil {
-			logger.Println(err)
-		} else if len(stathatEmail) > 0 {
-			stathat.PostEZCount("github.comments", stathatEmail, 1)
+		if err := queue.Enqueue(r); err != nil {
+			logger.Error(err)
+
+			code := http.StatusServiceUnavailable
+			if err == bot.ErrInvalidSignature {
+				code = http.StatusUnauthorized
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(code)
+			w.Write([]byte(`{"ok":false}`))
+			return
 		}
 	}
 
@@ -43,41 +69,156 @@ func helloHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"ok":true}`))
 }
 
-func main() {
-	logger = log.New(os.Stderr, "[hellobot] ", log.LstdFlags)
+// handled logs the error from a handled webhook delivery.
+func handled(err error) {
+	if bot.IsSkip(err) {
+		logger.Debug(err)
+	} else if err != nil {
+		logger.Error(err)
+	} else if len(stathatEmail) > 0 {
+		stathat.PostEZCount("github.comments", stathatEmail, 1)
+	}
+}
+
+func sweep(interval time.Duration) {
+	for range time.Tick(interval) {
+		actions, err := bt.Sweep()
+
+		for _, action := range actions {
+			logger.Info(action)
+		}
 
-	port := os.Getenv("PORT")
-	if len(port) == 0 {
-		logger.Fatal("PORT environment variable is required")
+		if err != nil {
+			logger.Error(err)
+		}
 	}
+}
+
+// fatal logs the error and exits.
+func fatal(err error) {
+	logger.Error(err)
+	os.Exit(1)
+}
 
-	if s := os.Getenv("STATHAT_EMAIL"); len(s) != 0 {
-		stathatEmail = s
+// loadKey loads and validates the private key from the configured source.
+func loadKey(config *server.Config) (*bot.PrivateKey, error) {
+	switch {
+	case len(config.PrivateKey) > 0:
+		return bot.NewPrivateKey([]byte(config.PrivateKey))
+	case len(config.PrivateKeyDir) > 0:
+		return bot.NewPrivateKeyFromDir(config.PrivateKeyDir)
+	default:
+		return bot.NewPrivateKeyFromFile(config.Cert)
 	}
+}
 
-	cert := os.Getenv("CERT")
-	if len(cert) == 0 {
-		logger.Fatal("CERT environment variable is required")
+func main() {
+	config, err := server.LoadFromEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	appID := os.Getenv("APP_ID")
-	if len(appID) == 0 {
-		logger.Fatal("APP_ID environment variable is required")
+	level, _ := server.ParseLevel(config.LogLevel)
+	logger = server.NewLogger(os.Stderr, level)
+
+	stathatEmail = config.StathatEmail
+
+	if len(config.RavenDSN) > 0 {
+		if err := raven.SetDSN(config.RavenDSN); err != nil {
+			fatal(err)
+		}
+
+		if err := raven.SetSampleRate(float32(config.RavenSampleRate)); err != nil {
+			fatal(err)
+		}
 	}
 
-	id, err := strconv.Atoi(appID)
+	exporter := trace.NewExporterFromEnv(func(err error) {
+		logger.Error(err)
+	})
+	trace.SetExporter(exporter)
+
+	key, err := loadKey(config)
 	if err != nil {
-		logger.Fatal(err)
+		fatal(err)
+	}
+
+	if config.KeyReload > 0 {
+		go key.Watch(config.KeyReload, nil, func(err error) {
+			if err != nil {
+				logger.Error(err)
+			} else {
+				logger.Info("Reloaded private key")
+			}
+		})
+	}
+
+	bt = bot.NewBot(config.AppID, key)
+
+	if err := bt.SetBaseURLs(config.GitHubURL, config.GitHubUploadURL); err != nil {
+		fatal(err)
+	}
+
+	if len(config.RavenDSN) > 0 {
+		bt.SetReporter(&bot.RavenReporter{Client: raven.DefaultClient})
+	}
+
+	if config.StorePath != "memory" {
+		store, err := bot.NewBoltStore(config.StorePath)
+		if err != nil {
+			fatal(err)
+		}
+		defer store.Close()
+
+		bt.SetStore(store)
 	}
 
-	bt = bot.NewBot(id, cert)
+	if config.SweepInterval > 0 {
+		go sweep(config.SweepInterval)
+	}
+
+	queue = bot.NewQueue(bt, config.QueueSize, handled)
+	queue.SetSecret(config.WebhookSecret)
+
+	expvar.Publish("hellobot.cache", expvar.Func(func() interface{} {
+		return bt.Stats()
+	}))
+
+	expvar.Publish("hellobot.queue", expvar.Func(func() interface{} {
+		return queue.Len()
+	}))
 
 	http.HandleFunc("/hello", raven.RecoveryHandler(helloHandler))
+	http.HandleFunc("/healthz", health.Healthz)
+	http.HandleFunc("/readyz", health.Readyz(map[string]health.Check{
+		"key": bt.CheckKey,
+		"queue": func() error {
+			if queue.Saturated() {
+				return errors.New("Queue is saturated")
+			}
+
+			return nil
+		},
+	}))
+	http.HandleFunc("/version", health.Version(commit, buildTime))
+
+	if len(config.AdminToken) != 0 {
+		http.Handle("/admin/", admin.NewHandler(&adminBot{Bot: bt, queue: queue}, config.AdminToken))
+	}
+	http.Handle("/r/", status.NewHandler(bt))
+	http.HandleFunc("/playground", playground.Handler)
 	http.Handle("/", http.FileServer(http.Dir("site/public")))
 
-	logger.Printf("Listening on http://0.0.0.0%s\n", ":"+port)
+	logger.Info("Listening on http://0.0.0.0:" + config.Port)
 	graceful.ListenAndServe(&http.Server{
-		Addr:    ":" + port,
+		Addr:    ":" + config.Port,
 		Handler: http.DefaultServeMux,
 	})
+
+	if e, ok := exporter.(*trace.OTLPExporter); ok {
+		if err := e.Shutdown(); err != nil {
+			logger.Error(err)
+		}
+	}
 }
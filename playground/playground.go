@@ -0,0 +1,36 @@
+// Package playground implements the endpoint that previews a `.hello.yml`
+// config for a sample issue or pull request.
+package playground
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/frozzare/hellobot/bot"
+)
+
+// maxBodySize is the max size of a playground request body.
+const maxBodySize = 64 << 10
+
+// Handler previews the config in the JSON request body and writes the result as JSON.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	var req bot.PreviewRequest
+
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(bot.Preview(req))
+}
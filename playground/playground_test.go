@@ -0,0 +1,40 @@
+package playground
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/frozzare/hellobot/bot"
+)
+
+func TestHandler(t *testing.T) {
+	body := `{"config": "issue:\n  message: Hello @{author}!\n  labels:\n    - triage", "event": "issues", "author": "monalisa"}`
+
+	w := httptest.NewRecorder()
+	Handler(w, httptest.NewRequest("POST", "/playground", strings.NewReader(body)))
+
+	var result bot.PreviewResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Comment != "Hello monalisa!" || len(result.Labels) != 1 || result.Labels[0] != "triage" || len(result.Errors) != 0 {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+
+	w = httptest.NewRecorder()
+	Handler(w, httptest.NewRequest("POST", "/playground", strings.NewReader("{")))
+
+	if w.Code != 400 {
+		t.Fatalf("Expected 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	Handler(w, httptest.NewRequest("GET", "/playground", nil))
+
+	if w.Code != 405 {
+		t.Fatalf("Expected 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,263 @@
+// Package server implements the configuration and logging of the hellobot
+// server.
+package server
+
+import (
+	"flag"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Config represents the server configuration.
+type Config struct {
+	Port            string        `yaml:"port"`
+	AppID           int           `yaml:"app_id"`
+	Cert            string        `yaml:"cert"`
+	PrivateKey      string        `yaml:"private_key"`
+	PrivateKeyDir   string        `yaml:"private_key_dir"`
+	KeyReload       time.Duration `yaml:"key_reload"`
+	WebhookSecret   string        `yaml:"webhook_secret"`
+	QueueSize       int           `yaml:"queue_size"`
+	StorePath       string        `yaml:"store_path"`
+	SweepInterval   time.Duration `yaml:"sweep_interval"`
+	GitHubURL       string        `yaml:"github_url"`
+	GitHubUploadURL string        `yaml:"github_upload_url"`
+	AdminToken      string        `yaml:"admin_token"`
+	StathatEmail    string        `yaml:"stathat_email"`
+	RavenDSN        string        `yaml:"raven_dsn"`
+	RavenSampleRate float64       `yaml:"raven_sample_rate"`
+	LogLevel        string        `yaml:"log_level"`
+}
+
+// Problems represents all problems found when loading the configuration.
+type Problems []string
+
+// Error returns all problems on one line each.
+func (p Problems) Error() string {
+	return "Invalid configuration:\n  " + strings.Join(p, "\n  ")
+}
+
+// setting represents a configuration setting that can be set with a flag
+// and an environment variable.
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"port", "PORT", "http port", func(c *Config, v string) error {
+		c.Port = v
+		return nil
+	}},
+	{"app-id", "APP_ID", "GitHub app id", func(c *Config, v string) (err error) {
+		c.AppID, err = strconv.Atoi(v)
+		return err
+	}},
+	{"cert", "CERT", "path to GitHub app private key", func(c *Config, v string) error {
+		c.Cert = v
+		return nil
+	}},
+	{"private-key", "PRIVATE_KEY", "GitHub app private key as PEM or base64 encoded PEM", func(c *Config, v string) error {
+		c.PrivateKey = v
+		return nil
+	}},
+	{"private-key-dir", "PRIVATE_KEY_DIR", "directory where the newest .pem file is the GitHub app private key", func(c *Config, v string) error {
+		c.PrivateKeyDir = v
+		return nil
+	}},
+	{"key-reload", "KEY_RELOAD", "how often the private key file is checked for changes, 0 disables it", func(c *Config, v string) (err error) {
+		c.KeyReload, err = time.ParseDuration(v)
+		return err
+	}},
+	{"webhook-secret", "WEBHOOK_SECRET", "GitHub app webhook secret", func(c *Config, v string) error {
+		c.WebhookSecret = v
+		return nil
+	}},
+	{"queue-size", "QUEUE_SIZE", "number of webhook deliveries that can be queued", func(c *Config, v string) (err error) {
+		c.QueueSize, err = strconv.Atoi(v)
+		return err
+	}},
+	{"store-path", "STORE_PATH", "path to BoltDB file where bot state is stored, memory keeps it in memory only", func(c *Config, v string) error {
+		c.StorePath = v
+		return nil
+	}},
+	{"sweep-interval", "SWEEP_INTERVAL", "how often scheduled sweeps runs, 0 disables them", func(c *Config, v string) (err error) {
+		c.SweepInterval, err = time.ParseDuration(v)
+		return err
+	}},
+	{"github-url", "GITHUB_URL", "GitHub API base url", func(c *Config, v string) error {
+		c.GitHubURL = v
+		return nil
+	}},
+	{"github-upload-url", "GITHUB_UPLOAD_URL", "GitHub upload base url, derived from the GitHub url when empty", func(c *Config, v string) error {
+		c.GitHubUploadURL = v
+		return nil
+	}},
+	{"admin-token", "ADMIN_TOKEN", "bearer token for the admin API", func(c *Config, v string) error {
+		c.AdminToken = v
+		return nil
+	}},
+	{"stathat-email", "STATHAT_EMAIL", "stathat email", func(c *Config, v string) error {
+		c.StathatEmail = v
+		return nil
+	}},
+	{"raven-dsn", "RAVEN_DSN", "Sentry raven dsn", func(c *Config, v string) error {
+		c.RavenDSN = v
+		return nil
+	}},
+	{"raven-sample-rate", "RAVEN_SAMPLE_RATE", "share of errors reported to Sentry", func(c *Config, v string) (err error) {
+		c.RavenSampleRate, err = strconv.ParseFloat(v, 64)
+		return err
+	}},
+	{"log-level", "LOG_LEVEL", "log level, debug, info or error", func(c *Config, v string) error {
+		c.LogLevel = v
+		return nil
+	}},
+}
+
+// DefaultConfig returns the configuration used for settings that are not set.
+func DefaultConfig() *Config {
+	return &Config{
+		KeyReload:       time.Minute,
+		QueueSize:       100,
+		StorePath:       "hellobot.db",
+		SweepInterval:   6 * time.Hour,
+		GitHubURL:       "https://api.github.com/",
+		RavenSampleRate: 1,
+		LogLevel:        "info",
+	}
+}
+
+// Load loads the configuration from the YAML file given with the `-config`
+// flag or the `CONFIG_FILE` environment variable, the environment variables
+// and the flags. Flags have precedence over environment variables that have
+// precedence over the file. All problems are returned at once.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	config := DefaultConfig()
+
+	var problems Problems
+
+	fs := flag.NewFlagSet("hellobot", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	path := fs.String("config", getenv("CONFIG_FILE"), "path to YAML config file")
+	for _, s := range settings {
+		fs.String(s.flag, "", s.usage+" ("+s.env+")")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, Problems{err.Error()}
+	}
+
+	// Problems with the file are reported with the problems of the other
+	// settings, which are still loaded.
+	if len(*path) > 0 {
+		data, err := ioutil.ReadFile(*path)
+		if err != nil {
+			problems = append(problems, errors.Wrap(err, "reading config file").Error())
+		} else if err := yaml.UnmarshalStrict(data, config); err != nil {
+			problems = append(problems, errors.Wrap(err, "unmarshal config file").Error())
+		}
+	}
+
+	for _, s := range settings {
+		if v := getenv(s.env); len(v) > 0 {
+			if err := s.set(config, v); err != nil {
+				problems = append(problems, "invalid "+s.env+": "+v)
+			}
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name {
+				if err := s.set(config, f.Value.String()); err != nil {
+					problems = append(problems, "invalid -"+s.flag+": "+f.Value.String())
+				}
+			}
+		}
+	})
+
+	problems = append(problems, config.Validate()...)
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	return config, nil
+}
+
+// LoadFromEnv loads the configuration from the command line arguments and
+// the environment.
+func LoadFromEnv() (*Config, error) {
+	return Load(os.Args[1:], os.Getenv)
+}
+
+// Validate returns all problems with the configuration.
+func (c *Config) Validate() Problems {
+	var problems Problems
+
+	if len(c.Port) == 0 {
+		problems = append(problems, "port is required")
+	}
+
+	if c.AppID <= 0 {
+		problems = append(problems, "app id is required")
+	}
+
+	sources := 0
+	for _, source := range []string{c.Cert, c.PrivateKey, c.PrivateKeyDir} {
+		if len(source) > 0 {
+			sources++
+		}
+	}
+
+	if sources == 0 {
+		problems = append(problems, "cert, private key or private key dir is required")
+	} else if sources > 1 {
+		problems = append(problems, "only one of cert, private key and private key dir can be used")
+	}
+
+	if c.KeyReload < 0 {
+		problems = append(problems, "key reload can't be negative")
+	}
+
+	if c.QueueSize <= 0 {
+		problems = append(problems, "queue size must be greater than 0")
+	}
+
+	if len(c.StorePath) == 0 {
+		problems = append(problems, "store path is required")
+	}
+
+	if c.SweepInterval < 0 {
+		problems = append(problems, "sweep interval can't be negative")
+	}
+
+	if u, err := url.Parse(c.GitHubURL); err != nil || !u.IsAbs() {
+		problems = append(problems, "github url must be an absolute url")
+	}
+
+	if u, err := url.Parse(c.GitHubUploadURL); len(c.GitHubUploadURL) > 0 && (err != nil || !u.IsAbs()) {
+		problems = append(problems, "github upload url must be an absolute url")
+	}
+
+	if c.RavenSampleRate < 0 || c.RavenSampleRate > 1 {
+		problems = append(problems, "raven sample rate must be between 0 and 1")
+	}
+
+	if _, err := ParseLevel(c.LogLevel); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	return problems
+}
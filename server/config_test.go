@@ -0,0 +1,124 @@
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hellobot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "hellobot.yml")
+	ioutil.WriteFile(path, []byte(`
+port: "3000"
+app_id: 1
+cert: hellobot.pem
+queue_size: 10
+sweep_interval: 1h
+log_level: debug
+`), 0644)
+
+	config, err := Load([]string{"-config", path, "-queue-size", "50"}, env(map[string]string{
+		"APP_ID":     "1234",
+		"QUEUE_SIZE": "20",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Port != "3000" || config.Cert != "hellobot.pem" || config.SweepInterval != time.Hour || config.LogLevel != "debug" {
+		t.Fatalf("Expected settings from file, got %+v", config)
+	}
+
+	if config.AppID != 1234 {
+		t.Fatalf("Expected environment variable to override file, got %d", config.AppID)
+	}
+
+	if config.QueueSize != 50 {
+		t.Fatalf("Expected flag to override environment variable, got %d", config.QueueSize)
+	}
+
+	if config.StorePath != "hellobot.db" || config.GitHubURL != "https://api.github.com/" {
+		t.Fatalf("Expected defaults, got %+v", config)
+	}
+}
+
+func TestLoadProblems(t *testing.T) {
+	_, err := Load([]string{"-log-level", "verbose"}, env(map[string]string{
+		"APP_ID":            "abc",
+		"RAVEN_SAMPLE_RATE": "2",
+		"GITHUB_URL":        "github",
+	}))
+
+	problems, ok := err.(Problems)
+	if !ok {
+		t.Fatalf("Expected problems, got %v", err)
+	}
+
+	expected := []string{
+		"invalid APP_ID: abc",
+		"port is required",
+		"app id is required",
+		"cert, private key or private key dir is required",
+		"github url must be an absolute url",
+		"raven sample rate must be between 0 and 1",
+		"unknown log level verbose",
+	}
+
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %q", len(expected), problems)
+	}
+
+	for i, problem := range expected {
+		if problems[i] != problem {
+			t.Fatalf("Expected %q, got %q", problem, problems[i])
+		}
+	}
+}
+
+func TestLoadUnknownSetting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hellobot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "hellobot.yml")
+	ioutil.WriteFile(path, []byte("prot: 3000\n"), 0644)
+
+	if _, err := Load(nil, env(map[string]string{"CONFIG_FILE": path})); err == nil {
+		t.Fatal("Expected error for unknown setting")
+	}
+}
+
+func TestLoadFileProblems(t *testing.T) {
+	_, err := Load([]string{"-config", "missing.yml"}, env(map[string]string{
+		"PORT":   "3000",
+		"APP_ID": "abc",
+		"CERT":   "hellobot.pem",
+	}))
+
+	problems, ok := err.(Problems)
+	if !ok {
+		t.Fatalf("Expected problems, got %v", err)
+	}
+
+	// Problems with the file are reported with the other problems.
+	if len(problems) != 3 || !strings.HasPrefix(problems[0], "reading config file") || problems[1] != "invalid APP_ID: abc" || problems[2] != "app id is required" {
+		t.Fatalf("Unexpected problems %q", problems)
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"io"
+	"log"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Level represents a log level.
+type Level int
+
+// Log levels, messages below the logger level are not logged.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelError
+)
+
+// ParseLevel parses a log level like `debug`, `info` or `error`.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return 0, errors.Errorf("unknown log level %s", s)
+	}
+}
+
+// Logger represents a leveled logger.
+type Logger struct {
+	logger *log.Logger
+	level  Level
+}
+
+// NewLogger creates a new logger that writes messages at the level and above.
+func NewLogger(w io.Writer, level Level) *Logger {
+	return &Logger{
+		logger: log.New(w, "[hellobot] ", log.LstdFlags),
+		level:  level,
+	}
+}
+
+// Debug logs a debug message.
+func (l *Logger) Debug(v ...interface{}) {
+	l.output(LevelDebug, v...)
+}
+
+// Info logs a info message.
+func (l *Logger) Info(v ...interface{}) {
+	l.output(LevelInfo, v...)
+}
+
+// Error logs a error message.
+func (l *Logger) Error(v ...interface{}) {
+	l.output(LevelError, v...)
+}
+
+// output logs the message if the level is enabled.
+func (l *Logger) output(level Level, v ...interface{}) {
+	if level >= l.level {
+		l.logger.Println(v...)
+	}
+}
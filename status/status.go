@@ -0,0 +1,146 @@
+// Package status implements the public repository status page and badge.
+package status
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/frozzare/hellobot/bot"
+)
+
+// cacheTTL is how long repository statuses are cached.
+const cacheTTL = 5 * time.Minute
+
+// nameRegexp matches valid owner and repository names.
+var nameRegexp = regexp.MustCompile(`^[\w.-]+$`)
+
+// Bot represents the bot methods used by the status page.
+type Bot interface {
+	RepositoryStatus(string, string) (*bot.RepositoryStatus, error)
+}
+
+type cacheItem struct {
+	status  *bot.RepositoryStatus
+	expires time.Time
+}
+
+// Handler represents the status page http handler.
+type Handler struct {
+	bot   Bot
+	mu    sync.Mutex
+	cache map[string]cacheItem
+	now   func() time.Time
+}
+
+// NewHandler creates a new status page handler.
+func NewHandler(b Bot) *Handler {
+	return &Handler{bot: b, cache: map[string]cacheItem{}, now: time.Now}
+}
+
+// ServeHTTP serves the status page at `/r/{owner}/{repo}` and the
+// badge at `/r/{owner}/{repo}/badge.svg`.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/r/"), "/"), "/")
+
+	if r.Method != "GET" || len(parts) < 2 || len(parts) > 3 || !nameRegexp.MatchString(parts[0]) || !nameRegexp.MatchString(parts[1]) {
+		http.NotFound(w, r)
+		return
+	}
+
+	status, err := h.status(parts[0], parts[1])
+	if err != nil {
+		http.Error(w, "Could not get repository status", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "max-age=300")
+
+	switch {
+	case len(parts) == 2:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		pageTemplate.Execute(w, status)
+	case parts[2] == "badge.svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		badgeTemplate.Execute(w, newBadge(status))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// status returns the cached repository status.
+func (h *Handler) status(owner, repo string) (*bot.RepositoryStatus, error) {
+	key := strings.ToLower(owner + "/" + repo)
+
+	h.mu.Lock()
+	item, ok := h.cache[key]
+	h.mu.Unlock()
+
+	if ok && h.now().Before(item.expires) {
+		return item.status, nil
+	}
+
+	status, err := h.bot.RepositoryStatus(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for k, item := range h.cache {
+		if !h.now().Before(item.expires) {
+			delete(h.cache, k)
+		}
+	}
+
+	h.cache[key] = cacheItem{status: status, expires: h.now().Add(cacheTTL)}
+
+	return status, nil
+}
+
+// badge represents the badge values.
+type badge struct {
+	Label  string
+	Value  string
+	Color  string
+	Width  int
+	Total  int
+	ValueX int
+}
+
+// newBadge returns the badge for the repository status.
+func newBadge(status *bot.RepositoryStatus) badge {
+	b := badge{Label: "hellobot", Value: "config valid", Color: "#4c1"}
+
+	switch {
+	case !status.Installed:
+		b.Value, b.Color = "not installed", "#9f9f9f"
+	case !status.ConfigFound:
+		b.Value, b.Color = "no config", "#9f9f9f"
+	case !status.ConfigValid():
+		b.Value, b.Color = "config invalid", "#e05d44"
+	}
+
+	// Approximate text width with 7px per character and padding.
+	b.Width = len(b.Value)*7 + 10
+	b.Total = 60 + b.Width
+	b.ValueX = 60 + b.Width/2
+
+	return b
+}
+
+// badgeTemplate only contains values from newBadge so it don't need html escaping.
+var badgeTemplate = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{ .Total }}" height="20" role="img" aria-label="{{ .Label }}: {{ .Value }}">
+<title>{{ .Label }}: {{ .Value }}</title>
+<rect width="60" height="20" fill="#555"/>
+<rect x="60" width="{{ .Width }}" height="20" fill="{{ .Color }}"/>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="30" y="14">{{ .Label }}</text>
+<text x="{{ .ValueX }}" y="14">{{ .Value }}</text>
+</g>
+</svg>
+`))
@@ -0,0 +1,83 @@
+package status
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/frozzare/hellobot/bot"
+)
+
+type fakeBot struct {
+	statuses map[string]*bot.RepositoryStatus
+	calls    int
+}
+
+func (f *fakeBot) RepositoryStatus(owner, repo string) (*bot.RepositoryStatus, error) {
+	f.calls++
+
+	if status, ok := f.statuses[owner+"/"+repo]; ok {
+		return status, nil
+	}
+
+	return &bot.RepositoryStatus{Repository: owner + "/" + repo}, nil
+}
+
+func get(h *Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	fake := &fakeBot{statuses: map[string]*bot.RepositoryStatus{
+		"frozzare/hellobot": {
+			Repository:   "frozzare/hellobot",
+			Installed:    true,
+			ConfigFound:  true,
+			IssueMessage: "Hello <b>@{author}</b>!",
+			Greetings:    3,
+		},
+		"frozzare/invalid": {
+			Repository:   "frozzare/invalid",
+			Installed:    true,
+			ConfigFound:  true,
+			ConfigErrors: []string{"issue: unknown reaction smile"},
+		},
+	}}
+
+	h := NewHandler(fake)
+
+	w := get(h, "/r/frozzare/hellobot")
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "Hello &lt;b&gt;@{author}&lt;/b&gt;!") || !strings.Contains(w.Body.String(), "3 greetings") {
+		t.Fatalf("Unexpected page %d %s", w.Code, w.Body.String())
+	}
+
+	badges := map[string]string{
+		"/r/frozzare/hellobot/badge.svg": "config valid",
+		"/r/frozzare/invalid/badge.svg":  "config invalid",
+		"/r/frozzare/other/badge.svg":    "not installed",
+	}
+
+	for path, value := range badges {
+		w := get(h, path)
+		if w.Header().Get("Content-Type") != "image/svg+xml" || !strings.Contains(w.Body.String(), ">"+value+"</text>") {
+			t.Errorf("%s: expected %s badge, got %s", path, value, w.Body.String())
+		}
+	}
+
+	if w := get(h, "/r/frozzare/invalid"); !strings.Contains(w.Body.String(), "unknown reaction smile") {
+		t.Fatalf("Expected config errors, got %s", w.Body.String())
+	}
+
+	// Statuses are cached.
+	if fake.calls != 3 {
+		t.Fatalf("Expected 3 calls, got %d", fake.calls)
+	}
+
+	for _, path := range []string{"/r/frozzare", "/r/frozzare/hellobot/other", "/r/frozzare/<script>"} {
+		if w := get(h, path); w.Code != 404 {
+			t.Errorf("%s: expected 404, got %d", path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package status
+
+import "html/template"
+
+var pageTemplate = template.Must(template.New("page").Parse(`<html>
+  <head>
+    <title>{{ .Repository }} - Hellobot</title>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <link href="//fonts.googleapis.com/css?family=Source+Sans+Pro:400,700" rel="stylesheet" type="text/css">
+    <link rel="stylesheet" href="/assets/css/styles.css">
+  </head>
+  <body>
+    <section class="section">
+      <div class="container">
+        <h1 class="title"><a href="https://github.com/{{ .Repository }}">{{ .Repository }}</a></h1>
+        <p><img src="/r/{{ .Repository }}/badge.svg" alt="Hellobot status"></p>
+        {{ if not .Installed }}
+        <p class="subtitle">Hellobot is not installed on this repository. <a href="https://github.com/apps/hellobot">Install Hellobot</a>.</p>
+        {{ else if not .ConfigFound }}
+        <p class="subtitle">Hellobot is installed but the repository has no <code>.hello.yml</code> file.</p>
+        {{ else }}
+        {{ if .ConfigValid }}
+        <p class="subtitle"><span class="tag is-success">.hello.yml is valid</span></p>
+        {{ else }}
+        <p class="subtitle"><span class="tag is-danger">.hello.yml is invalid</span></p>
+        <ul>
+          {{ range .ConfigErrors }}<li><code>{{ . }}</code></li>{{ end }}
+        </ul>
+        {{ end }}
+        <h2 class="title is-5">Issue message</h2>
+        <pre>{{ if .IssueMessage }}{{ .IssueMessage }}{{ else }}No message{{ end }}</pre>
+        <h2 class="title is-5">Pull request message</h2>
+        <pre>{{ if .PullRequestMessage }}{{ .PullRequestMessage }}{{ else }}No message{{ end }}</pre>
+        <p>{{ .Greetings }} greetings the last 30 days.</p>
+        {{ end }}
+        <h2 class="title is-5">Badge</h2>
+        <pre>[![Hellobot](https://www.hellobot.xyz/r/{{ .Repository }}/badge.svg)](https://www.hellobot.xyz/r/{{ .Repository }})</pre>
+      </div>
+    </section>
+  </body>
+</html>
+`))
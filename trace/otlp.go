@@ -0,0 +1,258 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// OTLPExporter exports spans in batches to an OpenTelemetry collector with
+// the OTLP/HTTP JSON protocol.
+type OTLPExporter struct {
+	endpoint string
+	headers  map[string]string
+	service  string
+	client   *http.Client
+
+	mu      sync.Mutex
+	spans   []*Span
+	maxSize int
+	done    chan struct{}
+	errors  func(error)
+}
+
+// NewOTLPExporter creates a new OTLP exporter that sends spans to the
+// traces endpoint, like `http://localhost:4318/v1/traces`, every interval or
+// when a batch is full. Export errors are passed to the errors function.
+func NewOTLPExporter(endpoint string, headers map[string]string, service string, interval time.Duration, errors func(error)) *OTLPExporter {
+	e := &OTLPExporter{
+		endpoint: endpoint,
+		headers:  headers,
+		service:  service,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		maxSize:  512,
+		done:     make(chan struct{}),
+		errors:   errors,
+	}
+
+	go e.run(interval)
+
+	return e
+}
+
+// NewExporterFromEnv creates the exporter configured by the standard
+// OpenTelemetry environment variables:
+//
+//	OTEL_EXPORTER_OTLP_TRACES_ENDPOINT  Traces endpoint, like http://localhost:4318/v1/traces.
+//	OTEL_EXPORTER_OTLP_ENDPOINT         Base endpoint, /v1/traces is appended.
+//	OTEL_EXPORTER_OTLP_HEADERS          Headers like key1=value1,key2=value2.
+//	OTEL_SERVICE_NAME                   Service name, defaults to hellobot.
+//
+// A no-op exporter is returned when no endpoint is configured.
+func NewExporterFromEnv(errors func(error)) Exporter {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if len(endpoint) == 0 {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); len(base) > 0 {
+			endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+		}
+	}
+
+	if len(endpoint) == 0 {
+		return NoopExporter{}
+	}
+
+	headers := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		if i := strings.Index(pair, "="); i > 0 {
+			headers[strings.TrimSpace(pair[:i])] = strings.TrimSpace(pair[i+1:])
+		}
+	}
+
+	service := os.Getenv("OTEL_SERVICE_NAME")
+	if len(service) == 0 {
+		service = "hellobot"
+	}
+
+	return NewOTLPExporter(endpoint, headers, service, 5*time.Second, errors)
+}
+
+// Export adds the span to the batch and sends the batch when it is full.
+func (e *OTLPExporter) Export(span *Span) {
+	e.mu.Lock()
+	e.spans = append(e.spans, span)
+	full := len(e.spans) >= e.maxSize
+	e.mu.Unlock()
+
+	if full {
+		go e.report(e.Flush())
+	}
+}
+
+// Flush sends the batched spans.
+func (e *OTLPExporter) Flush() error {
+	e.mu.Lock()
+	spans := e.spans
+	e.spans = nil
+	e.mu.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	buf, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return errors.Wrap(err, "marshal otlp request")
+	}
+
+	req, err := http.NewRequest("POST", e.endpoint, bytes.NewReader(buf))
+	if err != nil {
+		return errors.Wrap(err, "creating otlp request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "sending otlp request")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return errors.Errorf("otlp exporter: unexpected status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// Shutdown stops the batch interval and sends the remaining spans.
+func (e *OTLPExporter) Shutdown() error {
+	close(e.done)
+	return e.Flush()
+}
+
+// run sends the batched spans every interval.
+func (e *OTLPExporter) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.report(e.Flush())
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// report passes the error to the errors function.
+func (e *OTLPExporter) report(err error) {
+	if err != nil && e.errors != nil {
+		e.errors(err)
+	}
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// request returns the OTLP request for the spans.
+func (e *OTLPExporter) request(spans []*Span) *otlpRequest {
+	ss := otlpScopeSpans{Spans: []otlpSpan{}}
+	ss.Scope.Name = "github.com/frozzare/hellobot"
+
+	for _, span := range spans {
+		span.mu.Lock()
+
+		s := otlpSpan{
+			TraceID:           span.TraceID.String(),
+			SpanID:            span.SpanID.String(),
+			ParentSpanID:      span.ParentID.String(),
+			Name:              span.Name,
+			Kind:              1,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        attributes(span.Attributes),
+		}
+
+		// Status codes are unset (0), ok (1) and error (2).
+		if len(span.Error) > 0 {
+			s.Status = otlpStatus{Code: 2, Message: span.Error}
+		}
+
+		span.mu.Unlock()
+
+		ss.Spans = append(ss.Spans, s)
+	}
+
+	rs := otlpResourceSpans{ScopeSpans: []otlpScopeSpans{ss}}
+	rs.Resource.Attributes = attributes(map[string]string{"service.name": e.service})
+
+	return &otlpRequest{ResourceSpans: []otlpResourceSpans{rs}}
+}
+
+// attributes returns the OTLP attributes sorted by key.
+func attributes(m map[string]string) []otlpAttribute {
+	list := []otlpAttribute{}
+
+	for k, v := range m {
+		list = append(list, otlpAttribute{Key: k, Value: otlpValue{StringValue: v}})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key < list[j].Key
+	})
+
+	return list
+}
@@ -0,0 +1,202 @@
+// Package trace implements tracing spans for webhook handling and GitHub
+// calls and exporters that send them to OpenTelemetry collectors.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID represents a trace id.
+type TraceID [16]byte
+
+// String returns the hex encoded trace id.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID represents a span id.
+type SpanID [8]byte
+
+// String returns the hex encoded span id, or an empty string for the zero id.
+func (id SpanID) String() string {
+	if id == (SpanID{}) {
+		return ""
+	}
+
+	return hex.EncodeToString(id[:])
+}
+
+// Span represents a timed operation in a trace.
+type Span struct {
+	mu         sync.Mutex
+	TraceID    TraceID
+	SpanID     SpanID
+	ParentID   SpanID
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	Error      string
+	ended      bool
+}
+
+// Exporter exports ended spans.
+type Exporter interface {
+	Export(span *Span)
+}
+
+type contextKey int
+
+const (
+	spanKey contextKey = iota
+	attributesKey
+)
+
+var (
+	mu       sync.RWMutex
+	exporter Exporter = NoopExporter{}
+)
+
+// SetExporter sets the exporter ended spans are exported to.
+func SetExporter(e Exporter) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	exporter = e
+}
+
+// WithAttributes returns a context where all spans started from it gets the
+// attributes, like the repository and delivery id. Attributes are given as
+// key value pairs.
+func WithAttributes(ctx context.Context, kv ...string) context.Context {
+	attributes := map[string]string{}
+
+	if parent, ok := ctx.Value(attributesKey).(map[string]string); ok {
+		for k, v := range parent {
+			attributes[k] = v
+		}
+	}
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		attributes[kv[i]] = kv[i+1]
+	}
+
+	return context.WithValue(ctx, attributesKey, attributes)
+}
+
+// Start starts a span that is a child of the span in the context and returns
+// a context with the new span. Attributes are given as key value pairs.
+func Start(ctx context.Context, name string, kv ...string) (context.Context, *Span) {
+	span := &Span{
+		Name:       name,
+		Start:      time.Now(),
+		Attributes: map[string]string{},
+	}
+
+	if parent := FromContext(ctx); parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+	} else {
+		rand.Read(span.TraceID[:])
+	}
+
+	rand.Read(span.SpanID[:])
+
+	if attributes, ok := ctx.Value(attributesKey).(map[string]string); ok {
+		for k, v := range attributes {
+			span.Attributes[k] = v
+		}
+	}
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		span.Attributes[kv[i]] = kv[i+1]
+	}
+
+	return context.WithValue(ctx, spanKey, span), span
+}
+
+// FromContext returns the span in the context or nil.
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey).(*Span)
+	return span
+}
+
+// SetAttribute sets an attribute on the span.
+func (s *Span) SetAttribute(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Attributes[key] = value
+}
+
+// SetError marks the span as failed with the error, nil errors are ignored.
+func (s *Span) SetError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Error = err.Error()
+}
+
+// Finish ends the span and exports it, spans are only exported once.
+func (s *Span) Finish() {
+	s.mu.Lock()
+
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+
+	s.ended = true
+	s.End = time.Now()
+	s.mu.Unlock()
+
+	mu.RLock()
+	e := exporter
+	mu.RUnlock()
+
+	e.Export(s)
+}
+
+// NoopExporter drops all spans, it's the default exporter.
+type NoopExporter struct{}
+
+// Export drops the span.
+func (NoopExporter) Export(span *Span) {}
+
+// InMemoryExporter keeps exported spans in memory, used in tests.
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// Export keeps the span in memory.
+func (e *InMemoryExporter) Export(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the exported spans in the order they ended.
+func (e *InMemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]*Span{}, e.spans...)
+}
+
+// Reset removes the exported spans.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
@@ -0,0 +1,161 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStart(t *testing.T) {
+	exporter := &InMemoryExporter{}
+	SetExporter(exporter)
+	defer SetExporter(NoopExporter{})
+
+	ctx := WithAttributes(context.Background(), "github.delivery", "abc")
+	ctx, parent := Start(ctx, "parent")
+	_, child := Start(WithAttributes(ctx, "github.repository", "frozzare/hellobot"), "child", "rule", "paths")
+
+	child.SetError(errors.New("failed"))
+	child.Finish()
+	child.Finish()
+	parent.Finish()
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected two spans, got %d", len(spans))
+	}
+
+	if child.TraceID != parent.TraceID || child.ParentID != parent.SpanID {
+		t.Fatal("Expected child span to be in the parent trace")
+	}
+
+	if parent.ParentID.String() != "" {
+		t.Fatalf("Expected root span without parent, got %s", parent.ParentID)
+	}
+
+	if child.Attributes["github.delivery"] != "abc" || child.Attributes["github.repository"] != "frozzare/hellobot" || child.Attributes["rule"] != "paths" {
+		t.Fatalf("Expected inherited attributes, got %v", child.Attributes)
+	}
+
+	if _, ok := parent.Attributes["github.repository"]; ok {
+		t.Fatal("Expected parent span without child attributes")
+	}
+
+	if child.Error != "failed" {
+		t.Fatalf("Expected error, got %q", child.Error)
+	}
+
+	exporter.Reset()
+
+	if len(exporter.Spans()) != 0 {
+		t.Fatal("Expected no spans after reset")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	exporter := &InMemoryExporter{}
+	SetExporter(exporter)
+	defer SetExporter(NoopExporter{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx, parent := Start(context.Background(), "webhook.handle")
+
+	req, _ := http.NewRequest("GET", server.URL+"/repos/frozzare/hellobot", nil)
+	res, err := (&Transport{}).RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	spans := exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+
+	span := spans[0]
+
+	if span.Name != "github.api" || span.ParentID != parent.SpanID {
+		t.Fatalf("Expected github.api child span, got %s", span.Name)
+	}
+
+	if span.Attributes["http.path"] != "/repos/frozzare/hellobot" || span.Attributes["http.status_code"] != "502" {
+		t.Fatalf("Unexpected attributes %v", span.Attributes)
+	}
+
+	if len(span.Error) == 0 {
+		t.Fatal("Expected server errors to fail the span")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	requests := make(chan map[string]interface{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Api-Key") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var body map[string]interface{}
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		requests <- body
+	}))
+	defer server.Close()
+
+	e := NewOTLPExporter(server.URL+"/v1/traces", map[string]string{"api-key": "secret"}, "hellobot", time.Hour, nil)
+	SetExporter(e)
+	defer SetExporter(NoopExporter{})
+
+	_, span := Start(context.Background(), "webhook.handle", "github.delivery", "abc")
+	span.SetError(errors.New("failed"))
+	span.Finish()
+
+	if err := e.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	body := <-requests
+
+	data, _ := json.Marshal(body)
+	var req otlpRequest
+	json.Unmarshal(data, &req)
+
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("Unexpected request %s", data)
+	}
+
+	if attr := req.ResourceSpans[0].Resource.Attributes; len(attr) != 1 || attr[0].Value.StringValue != "hellobot" {
+		t.Fatalf("Expected service name, got %v", attr)
+	}
+
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+
+	s := spans[0]
+
+	if s.TraceID != span.TraceID.String() || s.SpanID != span.SpanID.String() || len(s.ParentSpanID) != 0 {
+		t.Fatalf("Unexpected ids %+v", s)
+	}
+
+	if s.Status.Code != 2 || s.Attributes[0].Key != "github.delivery" {
+		t.Fatalf("Unexpected span %+v", s)
+	}
+}
+
+func TestNewExporterFromEnv(t *testing.T) {
+	if _, ok := NewExporterFromEnv(nil).(NoopExporter); !ok {
+		t.Fatal("Expected no-op exporter without endpoint")
+	}
+}
@@ -0,0 +1,38 @@
+package trace
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// Transport is a http.RoundTripper that traces requests, like GitHub API
+// calls, as children of the span in the request context.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip traces and executes a single HTTP transaction.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx, span := Start(req.Context(), "github.api", "http.method", req.Method, "http.path", req.URL.Path)
+	defer span.Finish()
+
+	res, err := base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.SetError(err)
+		return res, err
+	}
+
+	span.SetAttribute("http.status_code", strconv.Itoa(res.StatusCode))
+
+	if res.StatusCode >= 500 {
+		span.SetError(errors.New(res.Status))
+	}
+
+	return res, nil
+}